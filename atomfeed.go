@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type AtomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr,omitempty"`
+	ID       string      `xml:"id,omitempty"`
+	Title    *AtomText   `xml:"title"`
+	Subtitle *AtomText   `xml:"subtitle,omitempty"`
+	Author   *AtomPerson `xml:"author,omitempty"`
+	Links    []AtomLink  `xml:"link"`
+	Updated  string      `xml:"updated,omitempty"`
+	Entry    []AtomEntry `xml:"entry"`
+}
+
+type AtomEntry struct {
+	ID        string      `xml:"id"`
+	Title     *AtomText   `xml:"title"`
+	Author    *AtomPerson `xml:"author,omitempty"`
+	Links     []AtomLink  `xml:"link"`
+	Updated   string      `xml:"updated,omitempty"`
+	Published string      `xml:"published,omitempty"`
+	Summary   *AtomText   `xml:"summary,omitempty"`
+	Content   *AtomText   `xml:"content,omitempty"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+// AtomText is an Atom text construct; xhtml content is kept as raw markup.
+type AtomText struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t *AtomText) String() string {
+	if t == nil {
+		return ""
+	}
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+func (f *AtomFeed) normalize() *Feed {
+	feed := &Feed{
+		Title:       f.Title.String(),
+		Link:        alternateLink(f.Links),
+		Description: f.Subtitle.String(),
+	}
+	for _, entry := range f.Entry {
+		link := alternateLink(entry.Links)
+		if link == "" {
+			link = entry.ID
+		}
+		description := entry.Summary.String()
+		if description == "" {
+			description = entry.Content.String()
+		}
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+		feedItem := FeedItem{
+			Title:       entry.Title.String(),
+			Link:        link,
+			Description: description,
+			PubDate:     strings.TrimSpace(pubDate),
+		}
+		for _, l := range entry.Links {
+			if l.Rel == "enclosure" {
+				feedItem.Attachments = append(feedItem.Attachments, FeedAttachment{
+					URL:      l.Href,
+					MimeType: l.Type,
+					Length:   l.Length,
+				})
+			}
+		}
+		feed.Items = append(feed.Items, feedItem)
+	}
+	return feed
+}
+
+// alternateLink picks the rel="alternate" link, which Atom treats as the
+// default when rel is omitted, falling back to the first link present.
+func alternateLink(links []AtomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	if len(links) > 0 {
+		return links[0].Href
+	}
+	return ""
+}
@@ -4,9 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,98 +22,218 @@ import (
 )
 
 type command struct {
-	name string
-	args []string
+	// ctx is cancelled when gator is asked to stop, e.g. with Ctrl-C.
+	ctx   context.Context
+	name  string
+	args  []string
+	flags *flag.FlagSet
+}
+
+// records printed by the listing commands in structured --output formats
+
+type userOutput struct {
+	apiUser
+	Current bool `json:"current"`
+}
+
+type feedStatusOutput struct {
+	Name                string     `json:"name"`
+	Url                 string     `json:"url"`
+	Status              string     `json:"status"`
+	LastFetchedAt       *time.Time `json:"last_fetched_at,omitempty"`
+	NextFetchAt         *time.Time `json:"next_fetch_at,omitempty"`
+	PollIntervalSeconds int32      `json:"poll_interval_seconds"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	LastStatusCode      *int32     `json:"last_status_code,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+}
+
+type browsePost struct {
+	apiPost
+	Cursor string `json:"cursor"`
+}
+
+type savedPost struct {
+	apiPost
+	SavedAt time.Time `json:"saved_at"`
+}
+
+type unreadCount struct {
+	Name   string `json:"feed"`
+	Url    string `json:"url"`
+	Unread int64  `json:"unread"`
 }
 
 func handlerLogin(s *state, cmd command) error {
-	if len(cmd.args) == 0 {
-		return errors.New("username required")
+	_, err := s.db.GetUser(cmd.ctx, cmd.args[0])
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundError("user %v not found", cmd.args[0])
 	}
-	_, err := s.db.GetUser(context.Background(), cmd.args[0])
 	if err != nil {
-		fmt.Println("user not found")
-		os.Exit(1)
+		return err
 	}
 	s.cfg.SetUser(cmd.args[0])
-	fmt.Printf("user %v logged in\n", cmd.args[0])
-	return nil
+	return s.out.message("user %v logged in", cmd.args[0])
 }
 
 func handlerRegister(s *state, cmd command) error {
-	if len(cmd.args) == 0 {
-		return errors.New("username required")
-	}
-	_, err := s.db.GetUser(context.Background(), cmd.args[0])
+	_, err := s.db.GetUser(cmd.ctx, cmd.args[0])
 	if err == nil {
-		fmt.Println("user with that name already exists")
-		os.Exit(1)
+		return alreadyExistsError("user %v already exists", cmd.args[0])
 	}
-	if err == sql.ErrNoRows {
-		user, err := s.db.CreateUser(context.Background(), database.CreateUserParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			Name:      cmd.args[0],
-		})
-		if err != nil {
-			return err
-		}
-		s.cfg.SetUser(user.Name)
-		fmt.Printf("user has been created: %v", user)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
 	}
-	return nil
+	user, err := s.db.CreateUser(cmd.ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      cmd.args[0],
+	})
+	if err != nil {
+		return err
+	}
+	s.cfg.SetUser(user.Name)
+	return renderItem(s.out, apiUser(user), func(apiUser) {
+		fmt.Printf("user has been created: %v\n", user)
+	})
 }
 
 func handlerReset(s *state, cmd command) error {
-	if err := s.db.Reset(context.Background()); err != nil {
-		fmt.Println("database reset failed", err)
-		os.Exit(1)
+	if err := s.db.Reset(cmd.ctx); err != nil {
+		return fmt.Errorf("database reset failed: %w", err)
 	}
-	fmt.Println("database reset successful")
-	os.Exit(0)
-	return nil
+	return s.out.message("database reset successful")
 }
 
 func handlerUsers(s *state, cmd command) error {
-	users, err := s.db.GetUsers(context.Background())
+	users, err := s.db.GetUsers(cmd.ctx)
 	if err != nil {
 		return err
 	}
+	var records []userOutput
 	for _, user := range users {
-		if user.Name == s.cfg.CurrentUserName {
+		records = append(records, userOutput{
+			apiUser: apiUser(user),
+			Current: user.Name == s.cfg.CurrentUserName,
+		})
+	}
+	return renderList(s.out, records, func(user userOutput) {
+		if user.Current {
 			fmt.Printf("* %v (current)\n", user.Name)
 		} else {
 			fmt.Printf("* %v\n", user.Name)
 		}
-	}
-	return nil
+	})
 }
 
 func handlerAgg(s *state, cmd command) error {
-	if len(cmd.args) == 0 {
-		return errors.New("required timestamp parameter, e.g. '1m' or '1h'")
+	once := cmd.boolFlag("once")
+	if len(cmd.args) == 0 && !once {
+		return invalidArgsError("an interval is required unless --once is given, e.g. 'gator agg 1m'")
+	}
+	var err error
+	timeDuration := defaultBaseInterval
+	if len(cmd.args) > 0 {
+		time_between_reqs := cmd.args[0]
+		timeDuration, err = time.ParseDuration(time_between_reqs)
+		if err != nil || timeDuration <= 0 {
+			return invalidArgsError("invalid interval '%s', expected a duration such as '1m' or '1h'", time_between_reqs)
+		}
+	}
+	workers := 1
+	if len(cmd.args) > 1 {
+		workers, err = strconv.Atoi(cmd.args[1])
+		if err != nil || workers < 1 {
+			return invalidArgsError("concurrency must be a positive integer")
+		}
+	}
+
+	slog.SetDefault(newAggLogger(s.out))
+	if addr := cmd.stringFlag("metrics-addr"); addr != "" {
+		stopMetrics, err := serveMetrics(s, addr)
+		if err != nil {
+			return err
+		}
+		defer stopMetrics()
+	}
+
+	if once {
+		err = s.out.message("Collecting due feeds once with %d worker(s)", workers)
+	} else {
+		err = s.out.message("Collecting feeds every %v with %d worker(s)", timeDuration, workers)
 	}
-	time_between_reqs := cmd.args[0]
-	timeDuration, err := time.ParseDuration(time_between_reqs)
 	if err != nil {
 		return err
 	}
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if once {
+				errs[i] = scrapeFeeds(cmd.ctx, s, timeDuration)
+				return
+			}
+			ticker := time.NewTicker(timeDuration)
+			defer ticker.Stop()
+			for {
+				if err := scrapeFeeds(cmd.ctx, s, timeDuration); err != nil {
+					slog.Error("claiming due feeds failed", "err", err)
+				}
+				select {
+				case <-cmd.ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(i)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-cmd.ctx.Done():
+		s.out.message("Stopping, finishing feeds already being fetched (interrupt again to abort)")
+		<-done
+	}
+	return errors.Join(errs...)
+}
 
-	fmt.Println("Collecting feeds every", timeDuration)
-	ticker := time.NewTicker(timeDuration)
-	for ; ; <-ticker.C {
-		scrapeFeeds(s)
+// newAggLogger logs to stderr, keeping stdout for the command's output, as
+// JSON when the output format is structured.
+func newAggLogger(out *output) *slog.Logger {
+	if out.format == "json" || out.format == "jsonl" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, nil))
 	}
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
 }
 
-func handlerAddfeed(s *state, cmd command, user database.User) error {
-	if len(cmd.args) < 2 {
-		fmt.Println("two parameters required: feed name and feed url")
-		os.Exit(1)
+// serveMetrics serves s.metrics until the returned function is called.
+func serveMetrics(s *state, addr string) (func(), error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
 	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", s.metrics)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != http.ErrServerClosed {
+			slog.Error("metrics server failed", "err", err)
+		}
+	}()
+	slog.Info("serving metrics", "addr", listener.Addr().String())
+	return func() { server.Close() }, nil
+}
 
-	feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
+func handlerAddfeed(s *state, cmd command, user database.User) error {
+	feed, err := s.db.CreateFeed(cmd.ctx, database.CreateFeedParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -114,10 +241,13 @@ func handlerAddfeed(s *state, cmd command, user database.User) error {
 		Url:       cmd.args[1],
 		UserID:    user.ID,
 	})
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return alreadyExistsError("feed %v already exists, follow it instead", cmd.args[1])
+	}
 	if err != nil {
 		return err
 	}
-	_, feedFollowErr := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+	_, feedFollowErr := s.db.CreateFeedFollow(cmd.ctx, database.CreateFeedFollowParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
@@ -127,99 +257,504 @@ func handlerAddfeed(s *state, cmd command, user database.User) error {
 	if feedFollowErr != nil {
 		return feedFollowErr
 	}
-	fmt.Println(feed)
-	os.Exit(0)
-	return nil
+	return renderItem(s.out, apiFeed{
+		ID:        &feed.ID,
+		CreatedAt: &feed.CreatedAt,
+		Name:      feed.Name,
+		Url:       feed.Url,
+		CreatedBy: user.Name,
+	}, func(apiFeed) {
+		fmt.Println(feed)
+	})
 }
 
 func handlerFeeds(s *state, cmd command) error {
-	feeds, err := s.db.GetFeeds(context.Background())
+	feeds, err := s.db.GetFeeds(cmd.ctx)
 	if err != nil {
 		return err
 	}
+	var records []apiFeed
 	for _, feed := range feeds {
-		fmt.Printf("* Name: %v, URL: %v, created by: %v\n", feed.Name, feed.Url, feed.Username)
+		records = append(records, apiFeed{
+			Name:      feed.Name,
+			Url:       feed.Url,
+			CreatedBy: feed.Username,
+		})
 	}
-	return nil
+	return renderList(s.out, records, func(feed apiFeed) {
+		fmt.Printf("* Name: %v, URL: %v, created by: %v\n", feed.Name, feed.Url, feed.CreatedBy)
+	})
+}
+
+func handlerFeedStatus(s *state, cmd command) error {
+	if len(cmd.args) > 0 && (len(cmd.args) != 2 || cmd.args[0] != "enable") {
+		return invalidArgsError("expected no arguments or 'enable <url>'")
+	}
+	if len(cmd.args) == 2 {
+		enabled, err := s.db.EnableFeed(cmd.ctx, cmd.args[1])
+		if err != nil {
+			return err
+		}
+		if enabled == 0 {
+			return notFoundError("feed %v not found", cmd.args[1])
+		}
+		return s.out.message("feed %v enabled", cmd.args[1])
+	}
+	statuses, err := s.db.GetFeedStatuses(cmd.ctx)
+	if err != nil {
+		return err
+	}
+	var records []feedStatusOutput
+	for _, status := range statuses {
+		record := feedStatusOutput{
+			Name:                status.Name,
+			Url:                 status.Url,
+			Status:              "ok",
+			LastFetchedAt:       nullTimePtr(status.LastFetchedAt),
+			NextFetchAt:         nullTimePtr(status.NextFetchAt),
+			PollIntervalSeconds: status.PollIntervalSeconds,
+			ConsecutiveFailures: status.ConsecutiveFailures,
+			LastError:           status.LastError.String,
+			DisabledAt:          nullTimePtr(status.DisabledAt),
+		}
+		if status.DisabledAt.Valid {
+			record.Status = "disabled"
+			record.NextFetchAt = nil
+		} else if status.ConsecutiveFailures > 0 {
+			record.Status = "failing"
+		}
+		if status.LastStatusCode.Valid {
+			record.LastStatusCode = &status.LastStatusCode.Int32
+		}
+		records = append(records, record)
+	}
+	return renderList(s.out, records, func(status feedStatusOutput) {
+		health := status.Status
+		if status.DisabledAt != nil {
+			health = fmt.Sprintf("disabled since %v", status.DisabledAt.Format(time.DateTime))
+		} else if status.ConsecutiveFailures > 0 {
+			health = fmt.Sprintf("failing (%d in a row)", status.ConsecutiveFailures)
+		}
+		fmt.Printf("* Name: %v, URL: %v, status: %v\n", status.Name, status.Url, health)
+		if status.LastFetchedAt != nil {
+			fmt.Printf("	* last fetched: %v\n", status.LastFetchedAt.Format(time.DateTime))
+		}
+		if status.NextFetchAt != nil {
+			fmt.Printf("	* next fetch: %v (every %v)\n", status.NextFetchAt.Format(time.DateTime), time.Duration(status.PollIntervalSeconds)*time.Second)
+		}
+		if status.LastStatusCode != nil {
+			fmt.Printf("	* last HTTP status: %v\n", *status.LastStatusCode)
+		}
+		if status.LastError != "" {
+			fmt.Printf("	* last error: %v\n", status.LastError)
+		}
+	})
 }
 
 func handlerFollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) == 0 {
-		os.Exit(1)
-		return errors.New("url required as argument")
+	feedToFollow, err := s.db.GetFeedByUrl(cmd.ctx, cmd.args[0])
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFoundError("feed %v not found, add it with addfeed", cmd.args[0])
 	}
-	feedToFollow, err := s.db.GetFeedByUrl(context.Background(), cmd.args[0])
 	if err != nil {
 		return err
 	}
-	feedFollow, err := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+	feedFollow, err := s.db.CreateFeedFollow(cmd.ctx, database.CreateFeedFollowParams{
 		ID:        uuid.New(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		UserID:    user.ID,
 		FeedID:    feedToFollow.ID,
 	})
-	fmt.Printf("Feed %v followed by %v", feedFollow.FeedName, feedFollow.UserName)
-	return nil
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return conflictError("already following %v", cmd.args[0])
+	}
+	if err != nil {
+		return err
+	}
+	return renderItem(s.out, apiFeedFollow{
+		ID:        feedFollow.ID,
+		CreatedAt: feedFollow.CreatedAt,
+		FeedID:    feedFollow.FeedID,
+		FeedName:  feedFollow.FeedName,
+		FeedUrl:   feedToFollow.Url,
+	}, func(apiFeedFollow) {
+		fmt.Printf("Feed %v followed by %v\n", feedFollow.FeedName, feedFollow.UserName)
+	})
 }
 
 func handlerFollowing(s *state, cmd command, user database.User) error {
-	feedsFollowed, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	feedsFollowed, err := s.db.GetFeedFollowsForUser(cmd.ctx, user.ID)
 	if err != nil {
 		return err
 	}
+	var records []apiFeedFollow
 	for _, feed := range feedsFollowed {
-		fmt.Println("* ", feed.FeedName)
+		records = append(records, apiFeedFollow{
+			ID:        feed.ID,
+			CreatedAt: feed.CreatedAt,
+			FeedID:    feed.FeedID,
+			FeedName:  feed.FeedName,
+			FeedUrl:   feed.FeedUrl,
+			Folder:    feed.Folder.String,
+		})
 	}
-	return nil
+	return renderList(s.out, records, func(feed apiFeedFollow) {
+		fmt.Println("* ", feed.FeedName)
+	})
 }
 
 func handlerUnfollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) == 0 {
-		os.Exit(1)
-		return errors.New("url required as argument")
-	}
-	if err := s.db.DeleteFeedFollow(context.Background(), database.DeleteFeedFollowParams{
+	removed, err := s.db.DeleteFeedFollow(cmd.ctx, database.DeleteFeedFollowParams{
 		UserID: user.ID,
 		Url:    cmd.args[0],
+	})
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return notFoundError("not following %v", cmd.args[0])
+	}
+	return s.out.message("unfollowed %v", cmd.args[0])
+}
+
+func handlerBrowse(s *state, cmd command, user database.User) error {
+	query := postQuery{
+		userID:   user.ID,
+		sort:     cmd.stringFlag("sort"),
+		pageSize: int32(cmd.intFlag("page-size")),
+	}
+	if len(cmd.args) == 1 {
+		// a bare number is the page size, as in earlier versions
+		size, err := strconv.Atoi(cmd.args[0])
+		if err != nil {
+			return invalidArgsError("page size must be a positive integer")
+		}
+		query.pageSize = int32(size)
+	}
+	if query.pageSize < 1 {
+		return invalidArgsError("page size must be a positive integer")
+	}
+	if !slices.Contains(browseSorts, query.sort) {
+		return invalidArgsError("sort must be one of %v", strings.Join(browseSorts, ", "))
+	}
+	if cmd.boolFlag("unread") && cmd.boolFlag("read") {
+		return invalidArgsError("use either --read or --unread, not both")
+	}
+	if cmd.boolFlag("unread") || cmd.boolFlag("read") {
+		query.read = sql.NullBool{Bool: cmd.boolFlag("read"), Valid: true}
+	}
+	if feed := cmd.stringFlag("feed"); feed != "" {
+		query.feed = sql.NullString{String: feed, Valid: true}
+	}
+	if cmd.flagGiven("since") && cmd.flagGiven("from") {
+		return invalidArgsError("use either --since or --from, not both")
+	}
+	for _, name := range []string{"since", "from"} {
+		if value := cmd.stringFlag(name); value != "" {
+			from, err := parseSince(value, time.Now())
+			if err != nil {
+				return invalidArgsError("invalid --%v: %v", name, err)
+			}
+			query.publishedFrom = sql.NullTime{Time: from, Valid: true}
+		}
+	}
+	if value := cmd.stringFlag("to"); value != "" {
+		to, err := parseUntil(value, time.Now())
+		if err != nil {
+			return invalidArgsError("invalid --to: %v", err)
+		}
+		query.publishedTo = sql.NullTime{Time: to, Valid: true}
+	}
+	if keyword := cmd.stringFlag("keyword"); keyword != "" {
+		query.keyword = sql.NullString{String: keyword, Valid: true}
+	}
+	for _, name := range []string{"before", "after"} {
+		value := cmd.stringFlag(name)
+		if value == "" {
+			continue
+		}
+		cursor, err := parsePostCursor(value)
+		if err != nil {
+			return err
+		}
+		if cursor.Sort != query.sort {
+			return invalidArgsError("cursor belongs to --sort %v", cursor.Sort)
+		}
+		if name == "before" {
+			query.before = &cursor
+		} else {
+			query.after = &cursor
+		}
+	}
+	if query.before != nil && query.after != nil {
+		return invalidArgsError("use either --before or --after, not both")
+	}
+
+	// one extra row tells us whether there is another page
+	pageSize := int(query.pageSize)
+	query.pageSize++
+	flag := "--before"
+	if query.after != nil {
+		flag = "--after"
+	}
+	posts, err := listPosts(cmd.ctx, s.db, query)
+	if err != nil {
+		return err
+	}
+	hasMore := len(posts) > pageSize
+	posts = posts[:min(len(posts), pageSize)]
+	var next postCursor
+	if len(posts) > 0 {
+		next = newPostCursor(query.sort, posts[len(posts)-1])
+	}
+	if query.after != nil {
+		// newer posts come back oldest first so the page starts right after
+		// the cursor; show them in the usual order
+		slices.Reverse(posts)
+	}
+
+	var records []browsePost
+	for _, post := range posts {
+		records = append(records, browsePost{
+			apiPost: apiPost{
+				ID:          post.ID,
+				Title:       post.Title,
+				Url:         post.Url,
+				Description: post.Description.String,
+				PublishedAt: nullTimePtr(post.PublishedAt),
+				FeedName:    post.FeedName,
+			},
+			Cursor: newPostCursor(query.sort, post).String(),
+		})
+		if err := markPostRead(cmd.ctx, s, user.ID, post.ID); err != nil {
+			return err
+		}
+	}
+	if len(posts) == 0 && s.out.format == "text" {
+		fmt.Println("no posts")
+	}
+	if err := renderList(s.out, records, func(post browsePost) {
+		fmt.Printf("* %v\n", post.Title)
+		if query.sort == "feed" {
+			fmt.Printf("	* feed: %v\n", post.FeedName)
+		}
+		fmt.Printf("	* %v\n", post.Description)
+		fmt.Printf("	* %v\n", post.Url)
+		fmt.Printf("	* id: %v\n", post.ID)
 	}); err != nil {
 		return err
 	}
+	if hasMore {
+		// structured output carries a cursor on every post, so the hint
+		// stays out of the way on stderr
+		hint := os.Stdout
+		if s.out.format != "text" {
+			hint = os.Stderr
+		}
+		fmt.Fprintf(hint, "next cursor: %v (pass it to %v)\n", next, flag)
+	}
 	return nil
 }
 
-func handlerBrowse(s *state, cmd command, user database.User) error {
-	limit := 0
-	if len(cmd.args) == 0 {
-		limit = 2
-	} else {
+func handlerRead(s *state, cmd command, user database.User) error {
+	postID, err := postIDArg(cmd)
+	if err != nil {
+		return err
+	}
+	if err := markPostRead(cmd.ctx, s, user.ID, postID); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" { // no post with that id
+			return notFoundError("post %v not found", postID)
+		}
+		return err
+	}
+	return s.out.message("post %v marked as read", postID)
+}
+
+func handlerUnread(s *state, cmd command, user database.User) error {
+	counts, err := s.db.CountUnreadPostsByFeed(cmd.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	var records []unreadCount
+	var total int64
+	for _, count := range counts {
+		records = append(records, unreadCount(count))
+		total += count.Unread
+	}
+	if err := renderList(s.out, records, func(count unreadCount) {
+		fmt.Printf("* %v: %d unread\n", count.Name, count.Unread)
+	}); err != nil {
+		return err
+	}
+	if s.out.format == "text" {
+		fmt.Printf("%d unread in total\n", total)
+	}
+	return nil
+}
 
-		limitArg, err := strconv.Atoi(cmd.args[0])
+func handlerSearch(s *state, cmd command, user database.User) error {
+	params := database.SearchPostsForUserParams{
+		UserID:      user.ID,
+		ResultLimit: int32(cmd.intFlag("limit")),
+	}
+	if params.ResultLimit < 1 {
+		return invalidArgsError("limit must be a positive integer")
+	}
+	if feed := cmd.stringFlag("feed"); feed != "" {
+		params.Feed = sql.NullString{String: feed, Valid: true}
+	}
+	if value := cmd.stringFlag("since"); value != "" {
+		since, err := parseSince(value, time.Now())
 		if err != nil {
-			limit = 2
-		} else {
-			limit = limitArg
+			return invalidArgsError("invalid --since: %v", err)
+		}
+		params.Since = sql.NullTime{Time: since, Valid: true}
+	}
+	params.Query = strings.Join(cmd.args, " ")
+	posts, err := s.db.SearchPostsForUser(cmd.ctx, params)
+	if err != nil {
+		return err
+	}
+	if len(posts) == 0 && s.out.format == "text" {
+		fmt.Println("no matching posts")
+	}
+	var records []apiPost
+	for _, post := range posts {
+		records = append(records, apiPost{
+			ID:          post.ID,
+			Title:       post.Title,
+			Url:         post.Url,
+			Description: post.Description.String,
+			PublishedAt: nullTimePtr(post.PublishedAt),
+			FeedName:    post.FeedName,
+		})
+	}
+	return renderList(s.out, records, func(post apiPost) {
+		fmt.Printf("* %v (%v)\n", post.Title, post.FeedName)
+		fmt.Printf("	* %v\n", post.Url)
+		fmt.Printf("	* id: %v\n", post.ID)
+	})
+}
+
+func handlerSave(s *state, cmd command, user database.User) error {
+	postID, err := postIDArg(cmd)
+	if err != nil {
+		return err
+	}
+	if err := s.db.SavePost(cmd.ctx, database.SavePostParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		PostID:    postID,
+	}); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" { // no post with that id
+			return notFoundError("post %v not found", postID)
 		}
+		return err
+	}
+	return s.out.message("post %v saved", postID)
+}
+
+func handlerUnsave(s *state, cmd command, user database.User) error {
+	postID, err := postIDArg(cmd)
+	if err != nil {
+		return err
 	}
-	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
+	removed, err := s.db.UnsavePost(cmd.ctx, database.UnsavePostParams{
 		UserID: user.ID,
-		Limit:  int32(limit),
+		PostID: postID,
 	})
 	if err != nil {
 		return err
 	}
+	if removed == 0 {
+		return notFoundError("post %v is not saved", postID)
+	}
+	return s.out.message("post %v unsaved", postID)
+}
+
+func handlerSaved(s *state, cmd command, user database.User) error {
+	posts, err := s.db.GetSavedPostsForUser(cmd.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	var records []savedPost
 	for _, post := range posts {
+		records = append(records, savedPost{
+			apiPost: apiPost{
+				ID:          post.ID,
+				Title:       post.Title,
+				Url:         post.Url,
+				Description: post.Description.String,
+			},
+			SavedAt: post.SavedAt,
+		})
+	}
+	return renderList(s.out, records, func(post savedPost) {
 		fmt.Printf("* %v\n", post.Title)
-		fmt.Printf("	* %v\n", post.Description.String)
+		fmt.Printf("	* %v\n", post.Description)
 		fmt.Printf("	* %v\n", post.Url)
+		fmt.Printf("	* id: %v, saved: %v\n", post.ID, post.SavedAt.Format(time.DateTime))
+	})
+}
+
+type attachmentOutput struct {
+	Url      string `json:"url"`
+	MimeType string `json:"mime_type,omitempty"`
+	Length   int64  `json:"length,omitempty"`
+}
+
+// handlerAttachments lists the enclosures and attachments a post came with.
+func handlerAttachments(s *state, cmd command) error {
+	postID, err := postIDArg(cmd)
+	if err != nil {
+		return err
 	}
-	return nil
+	if _, err := s.db.GetPostUrl(cmd.ctx, postID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFoundError("post %v not found", postID)
+		}
+		return err
+	}
+	attachments, err := s.db.GetPostAttachments(cmd.ctx, postID)
+	if err != nil {
+		return err
+	}
+	var records []attachmentOutput
+	for _, attachment := range attachments {
+		records = append(records, attachmentOutput{
+			Url:      attachment.Url,
+			MimeType: attachment.MimeType.String,
+			Length:   attachment.Length.Int64,
+		})
+	}
+	return renderList(s.out, records, func(attachment attachmentOutput) {
+		fmt.Printf("* %v\n", attachment.Url)
+		if attachment.MimeType != "" || attachment.Length > 0 {
+			fmt.Printf("	* type: %v, length: %v bytes\n", attachment.MimeType, attachment.Length)
+		}
+	})
+}
+
+func markPostRead(ctx context.Context, s *state, userID, postID uuid.UUID) error {
+	return s.db.MarkPostRead(ctx, database.MarkPostReadParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    userID,
+		PostID:    postID,
+	})
 }
 
 // middleware
 func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
 	return func(s *state, cmd command) error {
-		user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUserName)
+		user, err := s.db.GetUser(cmd.ctx, s.cfg.CurrentUserName)
+		if errors.Is(err, sql.ErrNoRows) {
+			return notFoundError("user %v not found, register or log in first", s.cfg.CurrentUserName)
+		}
 		if err != nil {
 			return err
 		}
@@ -228,27 +763,154 @@ func middlewareLoggedIn(handler func(s *state, cmd command, user database.User)
 }
 
 // aggregation
-func scrapeFeeds(s *state) error {
-	feedToFetch, err := s.db.GetNextFeedToFetch(context.Background())
-	if err != nil {
-		return err
+
+// scrapeFeeds keeps claiming due feeds until none are left or ctx is
+// cancelled. Claiming skips rows locked by other workers, so any number of
+// goroutines or agg processes can run it side by side.
+func scrapeFeeds(ctx context.Context, s *state, baseInterval time.Duration) error {
+	for ctx.Err() == nil {
+		now := time.Now()
+		feedToFetch, err := s.db.ClaimNextFeedToFetch(ctx, database.ClaimNextFeedToFetchParams{
+			Now: sql.NullTime{
+				Time: now, Valid: true,
+			},
+			LeaseUntil: sql.NullTime{
+				Time: now.Add(claimLease), Valid: true,
+			},
+		})
+		if err == sql.ErrNoRows || ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// a claimed feed is fetched and stored even if we are stopping, so
+		// shutting down never leaves it half done; scrapeFeed logs failures
+		scrapeFeed(context.WithoutCancel(ctx), s, feedToFetch, baseInterval)
 	}
-	if err := s.db.MarkFeedFetched(context.Background(), database.MarkFeedFetchedParams{
-		LastFetchedAt: sql.NullTime{
-			Time: time.Now(), Valid: true,
-		},
-		ID: feedToFetch.ID,
+	return nil
+}
+
+func scrapeFeed(ctx context.Context, s *state, feedToFetch database.ClaimNextFeedToFetchRow, baseInterval time.Duration) (err error) {
+	fetch := feedFetch{url: feedToFetch.Url}
+	defer func() {
+		fetch.err = err
+		logFeedFetch(fetch)
+		s.metrics.record(fetch)
+	}()
+
+	start := time.Now()
+	result, fetchErr := s.fetcher.fetch(ctx, feedToFetch.Url, cacheValidators{
+		etag:         feedToFetch.Etag.String,
+		lastModified: feedToFetch.LastModified.String,
+	})
+	fetch.duration = time.Since(start)
+	if result != nil {
+		fetch.statusCode = result.statusCode
+		fetch.bytes = result.bytes
+	}
+	if fetchErr == nil && result.feed != nil {
+		fetch.items = len(result.feed.Items)
+		var storeErr error
+		fetch.inserted, fetch.duplicates, storeErr = storePosts(ctx, s, feedToFetch.ID, result)
+		if storeErr != nil {
+			// counted and backed off like a failed fetch, so a feed we can't
+			// store is retried instead of being left claimed
+			fetchErr = fmt.Errorf("storing posts: %w", storeErr)
+		}
+	}
+	newPosts := fetch.inserted
+
+	now := time.Now()
+	prevInterval := time.Duration(feedToFetch.PollIntervalSeconds) * time.Second
+	var statusCode sql.NullInt32
+	if result != nil {
+		statusCode = sql.NullInt32{Int32: int32(result.statusCode), Valid: true}
+	}
+	if fetchErr != nil {
+		failure, err := s.db.RecordFeedFailure(ctx, database.RecordFeedFailureParams{
+			LastError: sql.NullString{
+				String: fetchErr.Error(), Valid: true,
+			},
+			LastStatusCode: statusCode,
+			DisableAfter:   feedFailureThreshold,
+			Now:            now,
+			ID:             feedToFetch.ID,
+		})
+		if err != nil {
+			return err
+		}
+		nextFetchAt := now.Add(failureBackoff(max(prevInterval, baseInterval), failure.ConsecutiveFailures))
+		if result != nil && now.Add(result.retryAfter).After(nextFetchAt) {
+			nextFetchAt = now.Add(result.retryAfter)
+		}
+		if err := scheduleFeedFetch(ctx, s, feedToFetch.ID, nextFetchAt, prevInterval); err != nil {
+			return err
+		}
+		if failure.DisabledAt.Valid {
+			return fmt.Errorf("%w (feed disabled after %d consecutive failures)", fetchErr, failure.ConsecutiveFailures)
+		}
+		return fetchErr
+	}
+
+	if err := s.db.RecordFeedSuccess(ctx, database.RecordFeedSuccessParams{
+		LastStatusCode: statusCode,
+		ID:             feedToFetch.ID,
 	}); err != nil {
 		return err
 	}
-	feed, err := fetchFeed(context.Background(), feedToFetch.Url)
-	if err != nil {
-		return err
+	interval := nextPollInterval(now, prevInterval, baseInterval, result, newPosts)
+	nextFetchAt := nextFetchTime(now, interval, result.feed)
+	if now.Add(result.retryAfter).After(nextFetchAt) {
+		nextFetchAt = now.Add(result.retryAfter)
 	}
-	for _, item := range feed.Channel.Item {
+	return scheduleFeedFetch(ctx, s, feedToFetch.ID, nextFetchAt, interval)
+}
+
+func logFeedFetch(fetch feedFetch) {
+	attrs := []any{
+		"feed", fetch.url,
+		"status", fetch.statusCode,
+		"duration", fetch.duration,
+		"bytes", fetch.bytes,
+		"items", fetch.items,
+		"inserted", fetch.inserted,
+		"duplicates", fetch.duplicates,
+	}
+	if fetch.err != nil {
+		slog.Error("fetching feed failed", append(attrs, "err", fetch.err)...)
+		return
+	}
+	slog.Info("fetched feed", attrs...)
+}
+
+func scheduleFeedFetch(ctx context.Context, s *state, feedID uuid.UUID, nextFetchAt time.Time, interval time.Duration) error {
+	return s.db.ScheduleFeedFetch(ctx, database.ScheduleFeedFetchParams{
+		NextFetchAt: sql.NullTime{
+			Time: nextFetchAt, Valid: true,
+		},
+		PollIntervalSeconds: int32(interval / time.Second),
+		ID:                  feedID,
+	})
+}
+
+// storePosts saves a freshly fetched feed and reports how many of its items
+// were new and how many were already stored.
+func storePosts(ctx context.Context, s *state, feedID uuid.UUID, result *fetchResult) (int, int, error) {
+	if result.feed.Link != "" {
+		if err := s.db.SetFeedSiteUrl(ctx, database.SetFeedSiteUrlParams{
+			SiteUrl: sql.NullString{String: result.feed.Link, Valid: true},
+			ID:      feedID,
+		}); err != nil {
+			return 0, 0, err
+		}
+	}
+	newPosts, duplicates := 0, 0
+	for _, item := range result.feed.Items {
 		publishedAt, _ := parseTime(item.PubDate)
-		if err := s.db.CreatePost(context.Background(), database.CreatePostParams{
-			ID:        uuid.New(),
+		postID := uuid.New()
+		if err := s.db.CreatePost(ctx, database.CreatePostParams{
+			ID:        postID,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 			Title:     item.Title,
@@ -261,21 +923,91 @@ func scrapeFeeds(s *state) error {
 				Time:  publishedAt,
 				Valid: true,
 			},
-			FeedID: feedToFetch.ID,
+			FeedID: feedID,
 		}); err != nil {
-			if pqErr, ok := err.(*pq.Error); ok {
-				if pqErr.Code == "23505" { // trying to insert already existing unique data
-					continue
-				} else {
-					return err
-				}
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" { // trying to insert already existing unique data
+				duplicates++
+				continue
 			}
+			return newPosts, duplicates, err
+		}
+		newPosts++
+		if err := storeAttachments(ctx, s, postID, item.Attachments); err != nil {
+			return newPosts, duplicates, err
+		}
+	}
+	// the validators are only saved once every item is stored; otherwise the
+	// next fetch would get 304 Not Modified and never retry the rest
+	if err := s.db.UpdateFeedCacheValidators(ctx, database.UpdateFeedCacheValidatorsParams{
+		Etag: sql.NullString{
+			String: result.cache.etag, Valid: result.cache.etag != "",
+		},
+		LastModified: sql.NullString{
+			String: result.cache.lastModified, Valid: result.cache.lastModified != "",
+		},
+		ID: feedID,
+	}); err != nil {
+		return newPosts, duplicates, err
+	}
+	return newPosts, duplicates, nil
+}
+
+func storeAttachments(ctx context.Context, s *state, postID uuid.UUID, attachments []FeedAttachment) error {
+	for _, attachment := range attachments {
+		if attachment.URL == "" {
+			continue
+		}
+		if err := s.db.CreatePostAttachment(ctx, database.CreatePostAttachmentParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			PostID:    postID,
+			Url:       attachment.URL,
+			MimeType: sql.NullString{
+				String: attachment.MimeType, Valid: attachment.MimeType != "",
+			},
+			Length: sql.NullInt64{
+				Int64: attachment.Length, Valid: attachment.Length > 0,
+			},
+		}); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // helpers
+
+// parseSince accepts either a duration back from now, such as "24h" or "7d",
+// or an absolute date.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	return parseTime(value)
+}
+
+// parseUntil is parseSince for the end of a range; a bare date includes the
+// whole day.
+func parseUntil(value string, now time.Time) (time.Time, error) {
+	if day, err := time.Parse(time.DateOnly, value); err == nil {
+		return day.AddDate(0, 0, 1), nil
+	}
+	return parseSince(value, now)
+}
+
+func postIDArg(cmd command) (uuid.UUID, error) {
+	postID, err := uuid.Parse(cmd.args[0])
+	if err != nil {
+		return uuid.Nil, invalidArgsError("invalid post id '%s'", cmd.args[0])
+	}
+	return postID, nil
+}
+
 func parseTime(dateStr string) (time.Time, error) {
 	layouts := []string{
 		time.RFC1123Z, // "Mon, 02 Jan 2006 15:04:05 -0700"
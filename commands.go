@@ -2,24 +2,268 @@ package main
 
 import (
 	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
 )
 
+// commandSpec describes a command: how it is invoked, what it accepts and
+// the handler that runs it.
+type commandSpec struct {
+	name string
+	// args is the synopsis of the positional arguments, e.g. "<name> <url>".
+	args    string
+	minArgs int
+	// maxArgs of -1 accepts any number of arguments.
+	maxArgs int
+	summary string
+	// flags declares the command's flags; handlers read them back with the
+	// command's flag getters.
+	flags   func(f *flag.FlagSet)
+	handler func(*state, command) error
+}
+
+func (spec *commandSpec) usage() string {
+	usage := "gator " + spec.name
+	if spec.flags != nil {
+		usage += " [flags]"
+	}
+	if spec.args != "" {
+		usage += " " + spec.args
+	}
+	return usage
+}
+
+// usageError reports a command invoked with the wrong arguments or flags.
+type usageError struct {
+	spec *commandSpec
+	msg  string
+}
+
+func (e *usageError) Error() string {
+	return fmt.Sprintf("%v\nusage: %v", e.msg, e.spec.usage())
+}
+
 type commands struct {
-	commands map[string]func(*state, command) error
+	commands map[string]*commandSpec
+	order    []string
 }
 
-func (c *commands) register(name string, f func(*state, command) error) {
-	c.commands[name] = f
+func (c *commands) register(spec commandSpec) {
+	if _, ok := c.commands[spec.name]; !ok {
+		c.order = append(c.order, spec.name)
+	}
+	c.commands[spec.name] = &spec
 }
 
 func (c *commands) run(s *state, cmd command) error {
-	cmdToRun, ok := c.commands[cmd.name]
-	if ok {
-		if err := cmdToRun(s, cmd); err != nil {
-			return err
+	spec, ok := c.commands[cmd.name]
+	if !ok {
+		if suggestion := c.suggest(cmd.name); suggestion != "" {
+			return invalidArgsError("command '%s' not found, did you mean '%s'?", cmd.name, suggestion)
 		}
-	} else {
-		return errors.New("command not found")
+		return invalidArgsError("command '%s' not found, run 'gator help' for a list of commands", cmd.name)
+	}
+
+	flags := flag.NewFlagSet(spec.name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	if spec.flags != nil {
+		spec.flags(flags)
+	}
+	args, err := parseFlags(flags, cmd.args)
+	if errors.Is(err, flag.ErrHelp) {
+		return renderCommandHelp(s.out, spec)
+	}
+	if err != nil {
+		msg := err.Error()
+		if i := strings.LastIndex(msg, " -"); i >= 0 {
+			msg = msg[:i] + " --" + msg[i+2:] // flags are documented with two dashes
+		}
+		return &usageError{spec: spec, msg: msg}
+	}
+	if len(args) < spec.minArgs {
+		return &usageError{spec: spec, msg: "not enough arguments"}
+	}
+	if spec.maxArgs >= 0 && len(args) > spec.maxArgs {
+		return &usageError{spec: spec, msg: fmt.Sprintf("unexpected argument '%s'", args[spec.maxArgs])}
 	}
-	return nil
+	cmd.args = args
+	cmd.flags = flags
+	return spec.handler(s, cmd)
+}
+
+// parseFlags parses flags wherever they appear among the positional
+// arguments, which the flag package alone stops at, and returns the
+// positional ones. Everything after "--" is positional.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := flags.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// suggest returns the registered command closest to a mistyped name, if
+// any is close enough.
+func (c *commands) suggest(name string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range c.order {
+		if strings.HasPrefix(candidate, name) && len(name) > 1 {
+			return candidate
+		}
+		if distance := editDistance(name, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
+
+// handlerHelp lists every command, or describes one in detail.
+func (c *commands) handlerHelp(s *state, cmd command) error {
+	if len(cmd.args) == 1 {
+		spec, ok := c.commands[cmd.args[0]]
+		if !ok {
+			return c.run(s, command{ctx: cmd.ctx, name: cmd.args[0]})
+		}
+		return renderCommandHelp(s.out, spec)
+	}
+	if s.out.format == "text" {
+		c.printHelp(os.Stdout)
+		return nil
+	}
+	var records []commandHelp
+	for _, name := range c.order {
+		records = append(records, c.commands[name].help())
+	}
+	return renderList(s.out, records, nil)
+}
+
+// commandHelp describes a command for the structured output formats.
+type commandHelp struct {
+	Name    string   `json:"name"`
+	Usage   string   `json:"usage"`
+	Summary string   `json:"summary"`
+	Flags   []string `json:"flags,omitempty"`
+}
+
+func (spec *commandSpec) help() commandHelp {
+	help := commandHelp{Name: spec.name, Usage: spec.usage(), Summary: spec.summary}
+	for _, f := range spec.flagHelp() {
+		help.Flags = append(help.Flags, f[0]+": "+f[1])
+	}
+	return help
+}
+
+// flagHelp lists the command's flags as pairs of synopsis and description.
+func (spec *commandSpec) flagHelp() [][2]string {
+	if spec.flags == nil {
+		return nil
+	}
+	flags := flag.NewFlagSet(spec.name, flag.ContinueOnError)
+	spec.flags(flags)
+	var lines [][2]string
+	flags.VisitAll(func(f *flag.Flag) {
+		name := "--" + f.Name
+		if _, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok {
+			name += " <value>"
+		}
+		usage := f.Usage
+		if f.DefValue != "" && f.DefValue != "false" {
+			usage += fmt.Sprintf(" (default %v)", f.DefValue)
+		}
+		lines = append(lines, [2]string{name, usage})
+	})
+	return lines
+}
+
+func (c *commands) printHelp(w io.Writer) {
+	fmt.Fprintln(w, "usage: gator [--output text|json|jsonl|csv|table] <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range c.order {
+		fmt.Fprintf(tw, "  %v\t%v\n", name, c.commands[name].summary)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'gator help <command>' or 'gator <command> --help' for details.")
+}
+
+func renderCommandHelp(out *output, spec *commandSpec) error {
+	return renderItem(out, spec.help(), func(commandHelp) {
+		printCommandHelp(os.Stdout, spec)
+	})
+}
+
+func printCommandHelp(w io.Writer, spec *commandSpec) {
+	fmt.Fprintf(w, "usage: %v\n\n%v\n", spec.usage(), spec.summary)
+	flags := spec.flagHelp()
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nflags:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range flags {
+		fmt.Fprintf(tw, "  %v\t%v\n", f[0], f[1])
+	}
+	tw.Flush()
+}
+
+// flag getters for handlers; the flags were declared by the command's spec
+
+func (cmd command) stringFlag(name string) string {
+	return cmd.flags.Lookup(name).Value.(flag.Getter).Get().(string)
+}
+
+func (cmd command) intFlag(name string) int {
+	return cmd.flags.Lookup(name).Value.(flag.Getter).Get().(int)
+}
+
+func (cmd command) boolFlag(name string) bool {
+	return cmd.flags.Lookup(name).Value.(flag.Getter).Get().(bool)
+}
+
+// flagGiven reports whether a flag was set on the command line rather than
+// left at its default.
+func (cmd command) flagGiven(name string) bool {
+	given := false
+	cmd.flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
 }
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/michalronin/gator/internal/database"
+)
+
+var browseSorts = []string{"published", "ingested", "feed"}
+
+// postCursor marks a position in a browse listing: the feed name when
+// sorting by feed, then the post's published or ingestion time (newest
+// first, undated posts last), with ties broken by id. Printed cursors are
+// opaque so the format can change without breaking scripts that pass them
+// back.
+type postCursor struct {
+	Sort  string     `json:"s"`
+	Group string     `json:"g,omitempty"`
+	Time  *time.Time `json:"t,omitempty"`
+	ID    uuid.UUID  `json:"i"`
+}
+
+func newPostCursor(sort string, post database.GetPostsForUserByPublishedRow) postCursor {
+	cursor := postCursor{Sort: sort, ID: post.ID}
+	sortTime := post.PublishedAt
+	switch sort {
+	case "ingested":
+		sortTime = sql.NullTime{Time: post.CreatedAt, Valid: true}
+	case "feed":
+		cursor.Group = post.FeedName
+	}
+	if sortTime.Valid {
+		t := sortTime.Time.UTC()
+		cursor.Time = &t
+	}
+	return cursor
+}
+
+func (c postCursor) sortTime() sql.NullTime {
+	if c.Time == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *c.Time, Valid: true}
+}
+
+func (c postCursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func parsePostCursor(value string) (postCursor, error) {
+	errInvalid := invalidArgsError("invalid cursor")
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return postCursor{}, errInvalid
+	}
+	var cursor postCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return postCursor{}, errInvalid
+	}
+	return cursor, nil
+}
+
+// postQuery selects a page of the posts from the feeds a user follows, in
+// one of the browseSorts orders, optionally before or after a cursor.
+type postQuery struct {
+	userID        uuid.UUID
+	sort          string
+	feed          sql.NullString
+	publishedFrom sql.NullTime
+	publishedTo   sql.NullTime
+	keyword       sql.NullString
+	read          sql.NullBool
+	before        *postCursor
+	after         *postCursor
+	pageSize      int32
+}
+
+// listPosts runs the keyset query for the sort order, each of which
+// compares on the indexed columns it orders by. Posts after a cursor come
+// back oldest first, so that the page starts right after it.
+func listPosts(ctx context.Context, db *database.Queries, q postQuery) ([]database.GetPostsForUserByPublishedRow, error) {
+	var cursorID uuid.NullUUID
+	var cursorTime sql.NullTime
+	var cursorFeed string
+	if q.before != nil {
+		cursorID = uuid.NullUUID{UUID: q.before.ID, Valid: true}
+		cursorTime = q.before.sortTime()
+		cursorFeed = q.before.Group
+	}
+	var posts []database.GetPostsForUserByPublishedRow
+	switch {
+	case q.sort == "ingested" && q.after != nil:
+		if q.after.Time == nil {
+			return nil, invalidArgsError("invalid cursor")
+		}
+		rows, err := db.GetPostsForUserByIngestedAfter(ctx, database.GetPostsForUserByIngestedAfterParams{
+			UserID:        q.userID,
+			Feed:          q.feed,
+			PublishedFrom: q.publishedFrom,
+			PublishedTo:   q.publishedTo,
+			Keyword:       q.keyword,
+			Read:          q.read,
+			CursorTime:    *q.after.Time,
+			CursorID:      q.after.ID,
+			PageSize:      q.pageSize,
+		})
+		for _, row := range rows {
+			posts = append(posts, database.GetPostsForUserByPublishedRow(row))
+		}
+		return posts, err
+	case q.sort == "ingested":
+		rows, err := db.GetPostsForUserByIngested(ctx, database.GetPostsForUserByIngestedParams{
+			UserID:        q.userID,
+			Feed:          q.feed,
+			PublishedFrom: q.publishedFrom,
+			PublishedTo:   q.publishedTo,
+			Keyword:       q.keyword,
+			Read:          q.read,
+			CursorID:      cursorID,
+			CursorTime:    cursorTime,
+			PageSize:      q.pageSize,
+		})
+		for _, row := range rows {
+			posts = append(posts, database.GetPostsForUserByPublishedRow(row))
+		}
+		return posts, err
+	case q.sort == "feed" && q.after != nil:
+		rows, err := db.GetPostsForUserByFeedAfter(ctx, database.GetPostsForUserByFeedAfterParams{
+			UserID:        q.userID,
+			Feed:          q.feed,
+			PublishedFrom: q.publishedFrom,
+			PublishedTo:   q.publishedTo,
+			Keyword:       q.keyword,
+			Read:          q.read,
+			CursorFeed:    q.after.Group,
+			CursorTime:    q.after.sortTime(),
+			CursorID:      q.after.ID,
+			PageSize:      q.pageSize,
+		})
+		for _, row := range rows {
+			posts = append(posts, database.GetPostsForUserByPublishedRow(row))
+		}
+		return posts, err
+	case q.sort == "feed":
+		rows, err := db.GetPostsForUserByFeed(ctx, database.GetPostsForUserByFeedParams{
+			UserID:        q.userID,
+			Feed:          q.feed,
+			PublishedFrom: q.publishedFrom,
+			PublishedTo:   q.publishedTo,
+			Keyword:       q.keyword,
+			Read:          q.read,
+			CursorID:      cursorID,
+			CursorFeed:    cursorFeed,
+			CursorTime:    cursorTime,
+			PageSize:      q.pageSize,
+		})
+		for _, row := range rows {
+			posts = append(posts, database.GetPostsForUserByPublishedRow(row))
+		}
+		return posts, err
+	case q.after != nil:
+		rows, err := db.GetPostsForUserByPublishedAfter(ctx, database.GetPostsForUserByPublishedAfterParams{
+			UserID:        q.userID,
+			Feed:          q.feed,
+			PublishedFrom: q.publishedFrom,
+			PublishedTo:   q.publishedTo,
+			Keyword:       q.keyword,
+			Read:          q.read,
+			CursorTime:    q.after.sortTime(),
+			CursorID:      q.after.ID,
+			PageSize:      q.pageSize,
+		})
+		for _, row := range rows {
+			posts = append(posts, database.GetPostsForUserByPublishedRow(row))
+		}
+		return posts, err
+	default:
+		return db.GetPostsForUserByPublished(ctx, database.GetPostsForUserByPublishedParams{
+			UserID:        q.userID,
+			Feed:          q.feed,
+			PublishedFrom: q.publishedFrom,
+			PublishedTo:   q.publishedTo,
+			Keyword:       q.keyword,
+			Read:          q.read,
+			CursorID:      cursorID,
+			CursorTime:    cursorTime,
+			PageSize:      q.pageSize,
+		})
+	}
+}
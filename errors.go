@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Exit codes, so scripts can tell failures apart. exitFailure covers
+// everything that isn't one of the kinds below, such as database errors.
+const (
+	exitFailure       = 1
+	exitInvalidArgs   = 2
+	exitNotFound      = 3
+	exitAlreadyExists = 4
+	exitConflict      = 5
+)
+
+// commandError is an error a handler expects, classified by the exit code
+// it maps to.
+type commandError struct {
+	code int
+	msg  string
+}
+
+func (e *commandError) Error() string {
+	return e.msg
+}
+
+func invalidArgsError(format string, a ...any) error {
+	return &commandError{code: exitInvalidArgs, msg: fmt.Sprintf(format, a...)}
+}
+
+func notFoundError(format string, a ...any) error {
+	return &commandError{code: exitNotFound, msg: fmt.Sprintf(format, a...)}
+}
+
+func alreadyExistsError(format string, a ...any) error {
+	return &commandError{code: exitAlreadyExists, msg: fmt.Sprintf(format, a...)}
+}
+
+func conflictError(format string, a ...any) error {
+	return &commandError{code: exitConflict, msg: fmt.Sprintf(format, a...)}
+}
+
+func exitCode(err error) int {
+	var cmdErr *commandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.code
+	}
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		return exitInvalidArgs
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505": // unique violation
+			return exitAlreadyExists
+		case "23503": // foreign key violation, the row referred to is gone
+			return exitNotFound
+		}
+	}
+	return exitFailure
+}
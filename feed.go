@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"time"
+)
+
+// Feed is the format-independent representation of a fetched feed that
+// scrapeFeeds works with, regardless of whether the source was RSS, Atom or
+// JSON Feed.
+type Feed struct {
+	Title       string
+	Link        string
+	Description string
+	Items       []FeedItem
+	// Publisher hints for how often the feed should be polled; only RSS
+	// carries them.
+	TTL       time.Duration
+	SkipHours []int
+	SkipDays  []time.Weekday
+}
+
+type FeedItem struct {
+	Title       string
+	Link        string
+	Description string
+	PubDate     string
+	Attachments []FeedAttachment
+}
+
+type FeedAttachment struct {
+	URL      string
+	MimeType string
+	Length   int64
+}
+
+func parseFeed(data []byte) (*Feed, error) {
+	if isJSONFeed(data) {
+		jsonFeed, err := decodeJSONFeed(data)
+		if err != nil {
+			return nil, err
+		}
+		return jsonFeed.normalize(), nil
+	}
+	root, err := rootElement(data)
+	if err != nil {
+		return nil, err
+	}
+	var feed *Feed
+	switch root {
+	case "rss":
+		var rss RSSFeed
+		if err := xml.Unmarshal(data, &rss); err != nil {
+			return nil, err
+		}
+		feed = rss.normalize()
+	case "feed":
+		var atom AtomFeed
+		if err := xml.Unmarshal(data, &atom); err != nil {
+			return nil, err
+		}
+		feed = atom.normalize()
+	default:
+		return nil, fmt.Errorf("unsupported feed format: <%s>", root)
+	}
+	feed.Title = html.UnescapeString(feed.Title)
+	feed.Description = html.UnescapeString(feed.Description)
+	for i := range feed.Items {
+		feed.Items[i].Title = html.UnescapeString(feed.Items[i].Title)
+		feed.Items[i].Description = html.UnescapeString(feed.Items[i].Description)
+	}
+	return feed, nil
+}
+
+func isJSONFeed(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n\ufeff")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// rootElement returns the local name of the first element in an XML document.
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", errors.New("could not find root element of feed document")
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
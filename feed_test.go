@@ -0,0 +1,209 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseAtomFeed(t *testing.T) {
+	tests := []struct {
+		name         string
+		doc          string
+		wantErr      string
+		wantLink     string
+		wantItemLink string
+		wantTitle    string
+		// wantDescription must be contained in the item's description
+		wantDescription string
+		wantPubDate     string
+	}{
+		{
+			name: "alternate link over self",
+			doc: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Blog</title>
+<link rel="self" href="https://example.com/feed.atom"/>
+<link rel="alternate" href="https://example.com/"/>
+<entry><id>urn:1</id><title>Post</title>
+<link rel="self" href="https://example.com/1.atom"/><link href="https://example.com/1"/>
+<published>2024-05-01T10:00:00Z</published><summary>Hi</summary></entry></feed>`,
+			wantLink:        "https://example.com/",
+			wantItemLink:    "https://example.com/1",
+			wantTitle:       "Post",
+			wantDescription: "Hi",
+			wantPubDate:     "2024-05-01T10:00:00Z",
+		},
+		{
+			name: "entry id without links",
+			doc: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Blog</title>
+<entry><id>https://example.com/posts/2</id><title type="html">A &amp;amp; B</title>
+<updated>2024-05-02T10:00:00Z</updated></entry></feed>`,
+			wantItemLink: "https://example.com/posts/2",
+			wantTitle:    "A & B",
+			wantPubDate:  "2024-05-02T10:00:00Z",
+		},
+		{
+			name: "xhtml content",
+			doc: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Blog</title>
+<entry><id>urn:3</id><title>Rich</title><link href="https://example.com/3"/>
+<content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml">Some <b>bold</b> text</div></content>
+</entry></feed>`,
+			wantItemLink:    "https://example.com/3",
+			wantTitle:       "Rich",
+			wantDescription: "Some <b>bold</b> text",
+		},
+		{
+			name: "updated when published is missing",
+			doc: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Blog</title>
+<entry><id>urn:4</id><title>Edited</title><link href="https://example.com/4"/>
+<updated> 2024-05-04T08:30:00+02:00 </updated></entry></feed>`,
+			wantItemLink: "https://example.com/4",
+			wantTitle:    "Edited",
+			wantPubDate:  "2024-05-04T08:30:00+02:00",
+		},
+		{
+			name:    "unsupported root element",
+			doc:     `<?xml version="1.0"?><html><body>Not a feed</body></html>`,
+			wantErr: "unsupported feed format: <html>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := parseFeed([]byte(tt.doc))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if feed.Link != tt.wantLink {
+				t.Errorf("feed link = %q, want %q", feed.Link, tt.wantLink)
+			}
+			if len(feed.Items) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Items))
+			}
+			item := feed.Items[0]
+			if item.Link != tt.wantItemLink {
+				t.Errorf("item link = %q, want %q", item.Link, tt.wantItemLink)
+			}
+			if item.Title != tt.wantTitle {
+				t.Errorf("item title = %q, want %q", item.Title, tt.wantTitle)
+			}
+			if !strings.Contains(item.Description, tt.wantDescription) {
+				t.Errorf("item description = %q, want it to contain %q", item.Description, tt.wantDescription)
+			}
+			if item.PubDate != tt.wantPubDate {
+				t.Errorf("item date = %q, want %q", item.PubDate, tt.wantPubDate)
+			}
+		})
+	}
+}
+
+func TestParseJSONFeed(t *testing.T) {
+	tests := []struct {
+		name            string
+		item            string
+		version         string
+		wantErr         string
+		wantLink        string
+		wantTitle       string
+		wantDescription string
+		wantAttachments []FeedAttachment
+	}{
+		{
+			name:            "url and summary",
+			item:            `{"id": "1", "url": "https://example.com/1", "external_url": "https://elsewhere.com/", "title": "Post", "summary": "Short", "content_html": "<p>Long</p>", "content_text": "Long"}`,
+			wantLink:        "https://example.com/1",
+			wantTitle:       "Post",
+			wantDescription: "Short",
+		},
+		{
+			name:            "external url and html content",
+			item:            `{"id": "2", "external_url": "https://elsewhere.com/2", "title": "Linked", "content_html": "<p>Body</p>", "content_text": "Body"}`,
+			wantLink:        "https://elsewhere.com/2",
+			wantTitle:       "Linked",
+			wantDescription: "<p>Body</p>",
+		},
+		{
+			name:            "id and text content",
+			item:            `{"id": "https://example.com/3", "title": "Plain", "content_text": "Just text"}`,
+			wantLink:        "https://example.com/3",
+			wantTitle:       "Plain",
+			wantDescription: "Just text",
+		},
+		{
+			name:            "untitled item uses its text",
+			item:            `{"id": "4", "url": "https://example.com/4", "content_text": "Saw a gator at the lake today"}`,
+			wantLink:        "https://example.com/4",
+			wantTitle:       "Saw a gator at the lake today",
+			wantDescription: "Saw a gator at the lake today",
+		},
+		{
+			name:            "untitled item uses its html",
+			item:            `{"id": "5", "url": "https://example.com/5", "content_html": "<p>Fish &amp; chips</p>"}`,
+			wantLink:        "https://example.com/5",
+			wantTitle:       "Fish & chips",
+			wantDescription: "<p>Fish &amp; chips</p>",
+		},
+		{
+			name:            "long untitled item is shortened",
+			item:            `{"id": "6", "url": "https://example.com/6", "content_text": "` + strings.Repeat("a", 100) + `"}`,
+			wantLink:        "https://example.com/6",
+			wantTitle:       strings.Repeat("a", jsonFeedTitleLength) + "…",
+			wantDescription: strings.Repeat("a", 100),
+		},
+		{
+			name:            "attachments",
+			item:            `{"id": "7", "url": "https://example.com/7", "title": "Episode", "attachments": [{"url": "https://example.com/7.mp3", "mime_type": "audio/mpeg", "size_in_bytes": 1234}, {"url": "https://example.com/7.jpg"}]}`,
+			wantLink:        "https://example.com/7",
+			wantTitle:       "Episode",
+			wantAttachments: []FeedAttachment{{URL: "https://example.com/7.mp3", MimeType: "audio/mpeg", Length: 1234}, {URL: "https://example.com/7.jpg"}},
+		},
+		{
+			name:    "bad version",
+			version: "1.1",
+			item:    `{"id": "8"}`,
+			wantErr: "unsupported JSON feed version: '1.1'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version := tt.version
+			if version == "" {
+				version = "https://jsonfeed.org/version/1.1"
+			}
+			doc := `{"version": "` + version + `", "title": "Blog", "home_page_url": "https://example.com/", "items": [` + tt.item + `]}`
+			feed, err := parseFeed([]byte(doc))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if feed.Link != "https://example.com/" {
+				t.Errorf("feed link = %q, want the home page", feed.Link)
+			}
+			if len(feed.Items) != 1 {
+				t.Fatalf("got %d items, want 1", len(feed.Items))
+			}
+			item := feed.Items[0]
+			if item.Link != tt.wantLink {
+				t.Errorf("item link = %q, want %q", item.Link, tt.wantLink)
+			}
+			if item.Title != tt.wantTitle {
+				t.Errorf("item title = %q, want %q", item.Title, tt.wantTitle)
+			}
+			if item.Description != tt.wantDescription {
+				t.Errorf("item description = %q, want %q", item.Description, tt.wantDescription)
+			}
+			if !slices.Equal(item.Attachments, tt.wantAttachments) {
+				t.Errorf("item attachments = %+v, want %+v", item.Attachments, tt.wantAttachments)
+			}
+		})
+	}
+}
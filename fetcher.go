@@ -0,0 +1,218 @@
+package main
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/michalronin/gator/internal/config"
+)
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3";
+// otherwise the module version from go install is used.
+var version = ""
+
+const (
+	defaultFetchTimeout   = 30 * time.Second
+	defaultConnectTimeout = 10 * time.Second
+	defaultMaxBodyBytes   = 10 << 20
+	defaultMaxRedirects   = 5
+)
+
+// feedFetcher downloads feed documents with the limits from the fetch
+// section of the config file.
+type feedFetcher struct {
+	client       *http.Client
+	userAgent    string
+	maxBodyBytes int64
+	maxRedirects int
+}
+
+func newFeedFetcher(cfg config.FetchConfig) (*feedFetcher, error) {
+	timeout, err := configDuration("fetch.timeout", cfg.Timeout, defaultFetchTimeout)
+	if err != nil {
+		return nil, err
+	}
+	connectTimeout, err := configDuration("fetch.connect_timeout", cfg.ConnectTimeout, defaultConnectTimeout)
+	if err != nil {
+		return nil, err
+	}
+	maxRedirects := defaultMaxRedirects
+	if cfg.MaxRedirects != nil {
+		maxRedirects = *cfg.MaxRedirects
+	}
+	maxBodyBytes := cfg.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultMaxBodyBytes
+	}
+	proxy := http.ProxyFromEnvironment
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid fetch.proxy '%s' in config", cfg.Proxy)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   connectTimeout,
+		ResponseHeaderTimeout: timeout,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConnsPerHost:   2,
+		ForceAttemptHTTP2:     true,
+		// we ask for compression ourselves so the body size limit applies to
+		// the decoded document
+		DisableCompression: true,
+	}
+	return &feedFetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > maxRedirects {
+					return http.ErrUseLastResponse // reported by fetch with its status
+				}
+				return nil
+			},
+		},
+		userAgent:    userAgent(),
+		maxBodyBytes: maxBodyBytes,
+		maxRedirects: maxRedirects,
+	}, nil
+}
+
+func configDuration(name, value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %v '%s' in config, expected a duration such as '30s'", name, value)
+	}
+	return d, nil
+}
+
+func userAgent() string {
+	v := version
+	if v == "" {
+		v = "devel"
+		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			v = info.Main.Version
+		}
+	}
+	return "gator/" + strings.TrimPrefix(v, "v") + " (+https://github.com/michalronin/gator)"
+}
+
+// cacheValidators holds the response headers used to make the next fetch of
+// a feed conditional.
+type cacheValidators struct {
+	etag         string
+	lastModified string
+}
+
+type fetchResult struct {
+	statusCode int
+	bytes      int
+	feed       *Feed // nil when the server answered 304 Not Modified
+	cache      cacheValidators
+	// maxAge and retryAfter are the Cache-Control max-age and Retry-After
+	// the server asked us to respect.
+	maxAge     time.Duration
+	retryAfter time.Duration
+}
+
+// fetch returns a result whenever the server responded, even alongside an
+// error, so that callers can record the status code and honor Retry-After.
+func (f *feedFetcher) fetch(ctx context.Context, feedURL string, cache cacheValidators) (*fetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, */*;q=0.8")
+	req.Header.Set("Accept-Encoding", "br, gzip, deflate")
+	if cache.etag != "" {
+		req.Header.Set("If-None-Match", cache.etag)
+	}
+	if cache.lastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.lastModified)
+	}
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	result := &fetchResult{
+		statusCode: res.StatusCode,
+		cache:      cache,
+		maxAge:     parseMaxAge(res.Header.Get("Cache-Control")),
+	}
+	result.retryAfter, _ = parseRetryAfter(res.Header.Get("Retry-After"), time.Now())
+	switch {
+	case res.StatusCode == http.StatusNotModified:
+		return result, nil
+	case res.StatusCode >= 300 && res.StatusCode < 400:
+		return result, fmt.Errorf("redirect not followed, status %v (at most %d redirects are followed)", res.Status, f.maxRedirects)
+	case res.StatusCode != http.StatusOK:
+		return result, fmt.Errorf("server responded with status %v", res.Status)
+	}
+	data, err := f.readBody(res)
+	result.bytes = len(data)
+	if err != nil {
+		return result, err
+	}
+	result.feed, err = parseFeed(data)
+	if err != nil {
+		return result, err
+	}
+	result.cache = cacheValidators{
+		etag:         res.Header.Get("ETag"),
+		lastModified: res.Header.Get("Last-Modified"),
+	}
+	return result, nil
+}
+
+// readBody decodes the response body and reads at most maxBodyBytes of it.
+func (f *feedFetcher) readBody(res *http.Response) ([]byte, error) {
+	var body io.Reader = res.Body
+	switch encoding := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("decoding gzip response: %w", err)
+		}
+		defer reader.Close()
+		body = reader
+	case "deflate":
+		reader, err := zlib.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("decoding deflate response: %w", err)
+		}
+		defer reader.Close()
+		body = reader
+	case "br":
+		body = brotli.NewReader(body)
+	default:
+		return nil, fmt.Errorf("unsupported content encoding '%s'", encoding)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, f.maxBodyBytes+1))
+	if err != nil {
+		return data, err
+	}
+	if int64(len(data)) > f.maxBodyBytes {
+		return data[:f.maxBodyBytes], fmt.Errorf("feed document is larger than %d bytes", f.maxBodyBytes)
+	}
+	return data, nil
+}
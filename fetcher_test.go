@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/michalronin/gator/internal/config"
+)
+
+const testFeedDocument = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Test</title>
+<item><title>First</title><link>https://example.com/1</link></item>
+<item><title>Second</title><link>https://example.com/2</link></item>
+</channel></rss>`
+
+func compressed(t *testing.T, encoding string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	case "br":
+		w = brotli.NewWriter(&buf)
+	}
+	if _, err := w.Write([]byte(testFeedDocument)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFeedFetcher(t *testing.T) {
+	var userAgent string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		io.WriteString(w, testFeedDocument)
+	})
+	for _, encoding := range []string{"gzip", "deflate", "br"} {
+		body := compressed(t, encoding)
+		mux.HandleFunc("/"+encoding, func(w http.ResponseWriter, r *http.Request) {
+			if !strings.Contains(r.Header.Get("Accept-Encoding"), encoding) {
+				t.Errorf("Accept-Encoding %q doesn't offer %v", r.Header.Get("Accept-Encoding"), encoding)
+			}
+			w.Header().Set("Content-Encoding", encoding)
+			w.Write(body)
+		})
+	}
+	mux.HandleFunc("/conditional", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, testFeedDocument)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "<html><body>Not found</body></html>")
+	})
+	mux.HandleFunc("/busy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.Handle("/moved", http.RedirectHandler("/feed", http.StatusMovedPermanently))
+	mux.Handle("/loop", http.RedirectHandler("/loop", http.StatusFound))
+	mux.HandleFunc("/huge", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte(" "), 2048))
+	})
+	mux.HandleFunc("/compressed-bomb", func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(bytes.Repeat([]byte(" "), 1<<20))
+		gz.Close()
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/zstd", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "zstd")
+		w.Write([]byte{0})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	maxRedirects := 2
+	fetcher, err := newFeedFetcher(config.FetchConfig{
+		Timeout:      "5s",
+		MaxBodyBytes: 1024,
+		MaxRedirects: &maxRedirects,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		cache      cacheValidators
+		wantStatus int
+		wantItems  int // -1 when no feed is parsed
+		wantErr    string
+		check      func(t *testing.T, result *fetchResult)
+	}{
+		{
+			name: "plain", path: "/feed", wantStatus: 200, wantItems: 2,
+			check: func(t *testing.T, result *fetchResult) {
+				if result.cache.etag != `"v1"` {
+					t.Errorf("etag = %q, want %q", result.cache.etag, `"v1"`)
+				}
+				if result.maxAge != time.Hour {
+					t.Errorf("maxAge = %v, want 1h from Cache-Control", result.maxAge)
+				}
+				if result.bytes != len(testFeedDocument) {
+					t.Errorf("bytes = %d, want %d", result.bytes, len(testFeedDocument))
+				}
+			},
+		},
+		{name: "gzip", path: "/gzip", wantStatus: 200, wantItems: 2},
+		{name: "deflate", path: "/deflate", wantStatus: 200, wantItems: 2},
+		{name: "brotli", path: "/br", wantStatus: 200, wantItems: 2},
+		{
+			name: "not modified", path: "/conditional", cache: cacheValidators{etag: `"v1"`},
+			wantStatus: 304, wantItems: -1,
+			check: func(t *testing.T, result *fetchResult) {
+				if result.cache.etag != `"v1"` {
+					t.Errorf("etag = %q, want the one sent to be kept", result.cache.etag)
+				}
+			},
+		},
+		{name: "modified", path: "/conditional", cache: cacheValidators{etag: `"v0"`}, wantStatus: 200, wantItems: 2},
+		{name: "html error page", path: "/missing", wantStatus: 404, wantItems: -1, wantErr: "404 Not Found"},
+		{
+			name: "retry after", path: "/busy", wantStatus: 503, wantItems: -1, wantErr: "503",
+			check: func(t *testing.T, result *fetchResult) {
+				if result.retryAfter != 2*time.Minute {
+					t.Errorf("retryAfter = %v, want 2m", result.retryAfter)
+				}
+			},
+		},
+		{name: "redirect followed", path: "/moved", wantStatus: 200, wantItems: 2},
+		{name: "too many redirects", path: "/loop", wantStatus: 302, wantItems: -1, wantErr: "at most 2 redirects"},
+		{name: "body too large", path: "/huge", wantStatus: 200, wantItems: -1, wantErr: "larger than 1024 bytes"},
+		{name: "decoded body too large", path: "/compressed-bomb", wantStatus: 200, wantItems: -1, wantErr: "larger than 1024 bytes"},
+		{name: "unsupported encoding", path: "/zstd", wantStatus: 200, wantItems: -1, wantErr: "unsupported content encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := fetcher.fetch(context.Background(), srv.URL+tt.path, tt.cache)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+			if result == nil {
+				t.Fatal("no result for a server that responded")
+			}
+			if result.statusCode != tt.wantStatus {
+				t.Errorf("statusCode = %d, want %d", result.statusCode, tt.wantStatus)
+			}
+			items := -1
+			if result.feed != nil {
+				items = len(result.feed.Items)
+			}
+			if items != tt.wantItems {
+				t.Errorf("items = %d, want %d", items, tt.wantItems)
+			}
+			if tt.check != nil {
+				tt.check(t, result)
+			}
+		})
+	}
+
+	if !strings.HasPrefix(userAgent, "gator/") {
+		t.Errorf("User-Agent = %q, want a versioned gator agent", userAgent)
+	}
+}
+
+func TestFeedFetcherTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+	fetcher, err := newFeedFetcher(config.FetchConfig{Timeout: "100ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := fetcher.fetch(context.Background(), srv.URL, cacheValidators{}); err == nil {
+		t.Fatal("fetch of a hanging server succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("fetch took %v, want it cut off by the timeout", elapsed)
+	}
+}
+
+func TestNewFeedFetcherConfig(t *testing.T) {
+	zero := 0
+	tests := []struct {
+		name    string
+		cfg     config.FetchConfig
+		wantErr bool
+	}{
+		{name: "defaults", cfg: config.FetchConfig{}},
+		{name: "everything set", cfg: config.FetchConfig{Timeout: "1m", ConnectTimeout: "5s", MaxBodyBytes: 1 << 20, MaxRedirects: &zero, Proxy: "http://proxy:3128"}},
+		{name: "bad timeout", cfg: config.FetchConfig{Timeout: "soon"}, wantErr: true},
+		{name: "negative connect timeout", cfg: config.FetchConfig{ConnectTimeout: "-1s"}, wantErr: true},
+		{name: "proxy without host", cfg: config.FetchConfig{Proxy: "proxy"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFeedFetcher(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
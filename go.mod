@@ -4,4 +4,10 @@ go 1.23.4
 
 require github.com/google/uuid v1.6.0
 
-require github.com/lib/pq v1.10.9 // indirect
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/lib/pq v1.10.9
+	golang.org/x/term v0.29.0
+)
+
+require golang.org/x/sys v0.30.0 // indirect
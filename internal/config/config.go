@@ -7,8 +7,21 @@ import (
 )
 
 type Config struct {
-	DbUrl           string `json:"db_url"`
-	CurrentUserName string `json:"current_user_name"`
+	DbUrl           string      `json:"db_url"`
+	CurrentUserName string      `json:"current_user_name"`
+	Fetch           FetchConfig `json:"fetch,omitempty"`
+}
+
+// FetchConfig tunes how agg downloads feeds; zero values use the defaults.
+type FetchConfig struct {
+	// Timeout and ConnectTimeout are durations such as "30s".
+	Timeout        string `json:"timeout,omitempty"`
+	ConnectTimeout string `json:"connect_timeout,omitempty"`
+	MaxBodyBytes   int64  `json:"max_body_bytes,omitempty"`
+	// MaxRedirects is a pointer so that 0 can turn redirects off.
+	MaxRedirects *int `json:"max_redirects,omitempty"`
+	// Proxy overrides the HTTP_PROXY and HTTPS_PROXY environment variables.
+	Proxy string `json:"proxy,omitempty"`
 }
 
 const configFileName = ".gatorconfig.json"
@@ -13,6 +13,48 @@ import (
 	"github.com/google/uuid"
 )
 
+const claimNextFeedToFetch = `-- name: ClaimNextFeedToFetch :one
+UPDATE feeds
+SET last_fetched_at = $1, updated_at = $1, next_fetch_at = $2
+WHERE feeds.id = (
+		SELECT id FROM feeds
+		WHERE disabled_at IS NULL
+		AND (next_fetch_at IS NULL OR next_fetch_at <= $1)
+		ORDER BY next_fetch_at NULLS FIRST
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+)
+RETURNING id, name, url, etag, last_modified, poll_interval_seconds
+`
+
+type ClaimNextFeedToFetchParams struct {
+	Now        sql.NullTime
+	LeaseUntil sql.NullTime
+}
+
+type ClaimNextFeedToFetchRow struct {
+	ID                  uuid.UUID
+	Name                string
+	Url                 string
+	Etag                sql.NullString
+	LastModified        sql.NullString
+	PollIntervalSeconds int32
+}
+
+func (q *Queries) ClaimNextFeedToFetch(ctx context.Context, arg ClaimNextFeedToFetchParams) (ClaimNextFeedToFetchRow, error) {
+	row := q.db.QueryRowContext(ctx, claimNextFeedToFetch, arg.Now, arg.LeaseUntil)
+	var i ClaimNextFeedToFetchRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.Etag,
+		&i.LastModified,
+		&i.PollIntervalSeconds,
+	)
+	return i, err
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES (
@@ -23,7 +65,7 @@ VALUES (
 		$5,
 		$6
 		)
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, poll_interval_seconds, consecutive_failures, last_error, last_status_code, disabled_at, site_url
 `
 
 type CreateFeedParams struct {
@@ -53,12 +95,35 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.PollIntervalSeconds,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastStatusCode,
+		&i.DisabledAt,
+		&i.SiteUrl,
 	)
 	return i, err
 }
 
+const enableFeed = `-- name: EnableFeed :execrows
+UPDATE feeds
+SET consecutive_failures = 0, disabled_at = NULL, next_fetch_at = NULL
+WHERE url = $1
+`
+
+func (q *Queries) EnableFeed(ctx context.Context, url string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, enableFeed, url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getFeedByUrl = `-- name: GetFeedByUrl :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, next_fetch_at, poll_interval_seconds, consecutive_failures, last_error, last_status_code, disabled_at, site_url FROM feeds WHERE url = $1
 `
 
 func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
@@ -72,10 +137,69 @@ func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.NextFetchAt,
+		&i.PollIntervalSeconds,
+		&i.ConsecutiveFailures,
+		&i.LastError,
+		&i.LastStatusCode,
+		&i.DisabledAt,
+		&i.SiteUrl,
 	)
 	return i, err
 }
 
+const getFeedStatuses = `-- name: GetFeedStatuses :many
+SELECT name, url, last_fetched_at, next_fetch_at, poll_interval_seconds, consecutive_failures, last_error, last_status_code, disabled_at FROM feeds
+ORDER BY name
+`
+
+type GetFeedStatusesRow struct {
+	Name                string
+	Url                 string
+	LastFetchedAt       sql.NullTime
+	NextFetchAt         sql.NullTime
+	PollIntervalSeconds int32
+	ConsecutiveFailures int32
+	LastError           sql.NullString
+	LastStatusCode      sql.NullInt32
+	DisabledAt          sql.NullTime
+}
+
+func (q *Queries) GetFeedStatuses(ctx context.Context) ([]GetFeedStatusesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedStatuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedStatusesRow
+	for rows.Next() {
+		var i GetFeedStatusesRow
+		if err := rows.Scan(
+			&i.Name,
+			&i.Url,
+			&i.LastFetchedAt,
+			&i.NextFetchAt,
+			&i.PollIntervalSeconds,
+			&i.ConsecutiveFailures,
+			&i.LastError,
+			&i.LastStatusCode,
+			&i.DisabledAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeeds = `-- name: GetFeeds :many
 SELECT feeds.name, feeds.url, users.name AS username FROM feeds
 INNER JOIN users ON feeds.user_id = users.id
@@ -110,37 +234,106 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, name, url FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
-LIMIT 1
+const recordFeedFailure = `-- name: RecordFeedFailure :one
+UPDATE feeds
+SET consecutive_failures = consecutive_failures + 1,
+		last_error = $1,
+		last_status_code = $2,
+		disabled_at = CASE
+				WHEN consecutive_failures + 1 >= $3::int THEN $4::timestamp
+		END
+WHERE feeds.id = $5
+RETURNING consecutive_failures, disabled_at
 `
 
-type GetNextFeedToFetchRow struct {
-	ID   uuid.UUID
-	Name string
-	Url  string
+type RecordFeedFailureParams struct {
+	LastError      sql.NullString
+	LastStatusCode sql.NullInt32
+	DisableAfter   int32
+	Now            time.Time
+	ID             uuid.UUID
+}
+
+type RecordFeedFailureRow struct {
+	ConsecutiveFailures int32
+	DisabledAt          sql.NullTime
 }
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (GetNextFeedToFetchRow, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
-	var i GetNextFeedToFetchRow
-	err := row.Scan(&i.ID, &i.Name, &i.Url)
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) (RecordFeedFailureRow, error) {
+	row := q.db.QueryRowContext(ctx, recordFeedFailure,
+		arg.LastError,
+		arg.LastStatusCode,
+		arg.DisableAfter,
+		arg.Now,
+		arg.ID,
+	)
+	var i RecordFeedFailureRow
+	err := row.Scan(&i.ConsecutiveFailures, &i.DisabledAt)
 	return i, err
 }
 
-const markFeedFetched = `-- name: MarkFeedFetched :exec
+const recordFeedSuccess = `-- name: RecordFeedSuccess :exec
 UPDATE feeds
-SET last_fetched_at = $1, updated_at = $1
+SET consecutive_failures = 0, last_error = NULL, last_status_code = $1
 WHERE feeds.id = $2
 `
 
-type MarkFeedFetchedParams struct {
-	LastFetchedAt sql.NullTime
-	ID            uuid.UUID
+type RecordFeedSuccessParams struct {
+	LastStatusCode sql.NullInt32
+	ID             uuid.UUID
+}
+
+func (q *Queries) RecordFeedSuccess(ctx context.Context, arg RecordFeedSuccessParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedSuccess, arg.LastStatusCode, arg.ID)
+	return err
+}
+
+const scheduleFeedFetch = `-- name: ScheduleFeedFetch :exec
+UPDATE feeds
+SET next_fetch_at = $1, poll_interval_seconds = $2
+WHERE feeds.id = $3
+`
+
+type ScheduleFeedFetchParams struct {
+	NextFetchAt         sql.NullTime
+	PollIntervalSeconds int32
+	ID                  uuid.UUID
+}
+
+func (q *Queries) ScheduleFeedFetch(ctx context.Context, arg ScheduleFeedFetchParams) error {
+	_, err := q.db.ExecContext(ctx, scheduleFeedFetch, arg.NextFetchAt, arg.PollIntervalSeconds, arg.ID)
+	return err
+}
+
+const setFeedSiteUrl = `-- name: SetFeedSiteUrl :exec
+UPDATE feeds
+SET site_url = $1
+WHERE feeds.id = $2
+`
+
+type SetFeedSiteUrlParams struct {
+	SiteUrl sql.NullString
+	ID      uuid.UUID
+}
+
+func (q *Queries) SetFeedSiteUrl(ctx context.Context, arg SetFeedSiteUrlParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedSiteUrl, arg.SiteUrl, arg.ID)
+	return err
+}
+
+const updateFeedCacheValidators = `-- name: UpdateFeedCacheValidators :exec
+UPDATE feeds
+SET etag = $1, last_modified = $2
+WHERE feeds.id = $3
+`
+
+type UpdateFeedCacheValidatorsParams struct {
+	Etag         sql.NullString
+	LastModified sql.NullString
+	ID           uuid.UUID
 }
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.LastFetchedAt, arg.ID)
+func (q *Queries) UpdateFeedCacheValidators(ctx context.Context, arg UpdateFeedCacheValidatorsParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedCacheValidators, arg.Etag, arg.LastModified, arg.ID)
 	return err
 }
@@ -52,40 +52,662 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) error {
 	return err
 }
 
-const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.title, posts.url, posts.description FROM posts 
+const getPostPageForUser = `-- name: GetPostPageForUser :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, feeds.name AS feed_name, feeds.url AS feed_url,
+		EXISTS (
+				SELECT 1 FROM post_reads
+				WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+		) AS read
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
 INNER JOIN feeds ON feeds.id = posts.feed_id
-WHERE feeds.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2
+WHERE feed_follows.user_id = $1
+AND ($2::text IS NULL OR feeds.url = $2)
+ORDER BY posts.published_at DESC NULLS LAST, posts.id
+LIMIT $3 OFFSET $4
 `
 
-type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+type GetPostPageForUserParams struct {
+	UserID     uuid.UUID
+	FeedUrl    sql.NullString
+	PageSize   int32
+	PageOffset int32
 }
 
-type GetPostsForUserRow struct {
+type GetPostPageForUserRow struct {
 	ID          uuid.UUID
 	Title       string
 	Url         string
 	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedName    string
+	FeedUrl     string
+	Read        bool
+}
+
+func (q *Queries) GetPostPageForUser(ctx context.Context, arg GetPostPageForUserParams) ([]GetPostPageForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostPageForUser,
+		arg.UserID,
+		arg.FeedUrl,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostPageForUserRow
+	for rows.Next() {
+		var i GetPostPageForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.Read,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostUrl = `-- name: GetPostUrl :one
+SELECT url FROM posts WHERE id = $1
+`
+
+func (q *Queries) GetPostUrl(ctx context.Context, id uuid.UUID) (string, error) {
+	row := q.db.QueryRowContext(ctx, getPostUrl, id)
+	var url string
+	err := row.Scan(&url)
+	return url, err
+}
+
+const getPostsForUserByFeed = `-- name: GetPostsForUserByFeed :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND ($2::text IS NULL OR feeds.name = $2 OR feeds.url = $2)
+AND ($3::timestamp IS NULL OR posts.published_at >= $3)
+AND ($4::timestamp IS NULL OR posts.published_at < $4)
+AND ($5::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', $5))
+AND ($6::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = $6)
+AND ($7::uuid IS NULL
+		OR feeds.name > $8::text
+		OR (feeds.name = $8::text AND (
+				(posts.published_at, posts.id) < ($9::timestamp, $7::uuid)
+				OR (posts.published_at IS NULL AND ($9::timestamp IS NOT NULL OR posts.id < $7::uuid))
+		))
+)
+ORDER BY feeds.name ASC, posts.published_at DESC NULLS LAST, posts.id DESC
+LIMIT $10
+`
+
+type GetPostsForUserByFeedParams struct {
+	UserID        uuid.UUID
+	Feed          sql.NullString
+	PublishedFrom sql.NullTime
+	PublishedTo   sql.NullTime
+	Keyword       sql.NullString
+	Read          sql.NullBool
+	CursorID      uuid.NullUUID
+	CursorFeed    string
+	CursorTime    sql.NullTime
+	PageSize      int32
+}
+
+type GetPostsForUserByFeedRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	CreatedAt   time.Time
+	FeedName    string
+	FeedUrl     string
+}
+
+func (q *Queries) GetPostsForUserByFeed(ctx context.Context, arg GetPostsForUserByFeedParams) ([]GetPostsForUserByFeedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByFeed,
+		arg.UserID,
+		arg.Feed,
+		arg.PublishedFrom,
+		arg.PublishedTo,
+		arg.Keyword,
+		arg.Read,
+		arg.CursorID,
+		arg.CursorFeed,
+		arg.CursorTime,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserByFeedRow
+	for rows.Next() {
+		var i GetPostsForUserByFeedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByFeedAfter = `-- name: GetPostsForUserByFeedAfter :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND ($2::text IS NULL OR feeds.name = $2 OR feeds.url = $2)
+AND ($3::timestamp IS NULL OR posts.published_at >= $3)
+AND ($4::timestamp IS NULL OR posts.published_at < $4)
+AND ($5::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', $5))
+AND ($6::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = $6)
+AND (feeds.name < $7::text
+		OR (feeds.name = $7::text AND (
+				(posts.published_at, posts.id) > ($8::timestamp, $9::uuid)
+				OR ($8::timestamp IS NULL AND (posts.published_at IS NOT NULL OR posts.id > $9::uuid))
+		))
+)
+ORDER BY feeds.name DESC, posts.published_at ASC NULLS FIRST, posts.id ASC
+LIMIT $10
+`
+
+type GetPostsForUserByFeedAfterParams struct {
+	UserID        uuid.UUID
+	Feed          sql.NullString
+	PublishedFrom sql.NullTime
+	PublishedTo   sql.NullTime
+	Keyword       sql.NullString
+	Read          sql.NullBool
+	CursorFeed    string
+	CursorTime    sql.NullTime
+	CursorID      uuid.UUID
+	PageSize      int32
+}
+
+type GetPostsForUserByFeedAfterRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	CreatedAt   time.Time
+	FeedName    string
+	FeedUrl     string
+}
+
+func (q *Queries) GetPostsForUserByFeedAfter(ctx context.Context, arg GetPostsForUserByFeedAfterParams) ([]GetPostsForUserByFeedAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByFeedAfter,
+		arg.UserID,
+		arg.Feed,
+		arg.PublishedFrom,
+		arg.PublishedTo,
+		arg.Keyword,
+		arg.Read,
+		arg.CursorFeed,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserByFeedAfterRow
+	for rows.Next() {
+		var i GetPostsForUserByFeedAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByIngested = `-- name: GetPostsForUserByIngested :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND ($2::text IS NULL OR feeds.name = $2 OR feeds.url = $2)
+AND ($3::timestamp IS NULL OR posts.published_at >= $3)
+AND ($4::timestamp IS NULL OR posts.published_at < $4)
+AND ($5::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', $5))
+AND ($6::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = $6)
+AND ($7::uuid IS NULL
+		OR (posts.created_at, posts.id) < ($8::timestamp, $7::uuid)
+)
+ORDER BY posts.created_at DESC, posts.id DESC
+LIMIT $9
+`
+
+type GetPostsForUserByIngestedParams struct {
+	UserID        uuid.UUID
+	Feed          sql.NullString
+	PublishedFrom sql.NullTime
+	PublishedTo   sql.NullTime
+	Keyword       sql.NullString
+	Read          sql.NullBool
+	CursorID      uuid.NullUUID
+	CursorTime    sql.NullTime
+	PageSize      int32
+}
+
+type GetPostsForUserByIngestedRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	CreatedAt   time.Time
+	FeedName    string
+	FeedUrl     string
+}
+
+func (q *Queries) GetPostsForUserByIngested(ctx context.Context, arg GetPostsForUserByIngestedParams) ([]GetPostsForUserByIngestedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByIngested,
+		arg.UserID,
+		arg.Feed,
+		arg.PublishedFrom,
+		arg.PublishedTo,
+		arg.Keyword,
+		arg.Read,
+		arg.CursorID,
+		arg.CursorTime,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserByIngestedRow
+	for rows.Next() {
+		var i GetPostsForUserByIngestedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByIngestedAfter = `-- name: GetPostsForUserByIngestedAfter :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND ($2::text IS NULL OR feeds.name = $2 OR feeds.url = $2)
+AND ($3::timestamp IS NULL OR posts.published_at >= $3)
+AND ($4::timestamp IS NULL OR posts.published_at < $4)
+AND ($5::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', $5))
+AND ($6::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = $6)
+AND (posts.created_at, posts.id) > ($7::timestamp, $8::uuid)
+ORDER BY posts.created_at ASC, posts.id ASC
+LIMIT $9
+`
+
+type GetPostsForUserByIngestedAfterParams struct {
+	UserID        uuid.UUID
+	Feed          sql.NullString
+	PublishedFrom sql.NullTime
+	PublishedTo   sql.NullTime
+	Keyword       sql.NullString
+	Read          sql.NullBool
+	CursorTime    time.Time
+	CursorID      uuid.UUID
+	PageSize      int32
+}
+
+type GetPostsForUserByIngestedAfterRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	CreatedAt   time.Time
+	FeedName    string
+	FeedUrl     string
+}
+
+func (q *Queries) GetPostsForUserByIngestedAfter(ctx context.Context, arg GetPostsForUserByIngestedAfterParams) ([]GetPostsForUserByIngestedAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByIngestedAfter,
+		arg.UserID,
+		arg.Feed,
+		arg.PublishedFrom,
+		arg.PublishedTo,
+		arg.Keyword,
+		arg.Read,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserByIngestedAfterRow
+	for rows.Next() {
+		var i GetPostsForUserByIngestedAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByPublished = `-- name: GetPostsForUserByPublished :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND ($2::text IS NULL OR feeds.name = $2 OR feeds.url = $2)
+AND ($3::timestamp IS NULL OR posts.published_at >= $3)
+AND ($4::timestamp IS NULL OR posts.published_at < $4)
+AND ($5::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', $5))
+AND ($6::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = $6)
+AND ($7::uuid IS NULL
+		OR (posts.published_at, posts.id) < ($8::timestamp, $7::uuid)
+		OR (posts.published_at IS NULL AND ($8::timestamp IS NOT NULL OR posts.id < $7::uuid))
+)
+ORDER BY posts.published_at DESC NULLS LAST, posts.id DESC
+LIMIT $9
+`
+
+type GetPostsForUserByPublishedParams struct {
+	UserID        uuid.UUID
+	Feed          sql.NullString
+	PublishedFrom sql.NullTime
+	PublishedTo   sql.NullTime
+	Keyword       sql.NullString
+	Read          sql.NullBool
+	CursorID      uuid.NullUUID
+	CursorTime    sql.NullTime
+	PageSize      int32
+}
+
+type GetPostsForUserByPublishedRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	CreatedAt   time.Time
+	FeedName    string
+	FeedUrl     string
+}
+
+func (q *Queries) GetPostsForUserByPublished(ctx context.Context, arg GetPostsForUserByPublishedParams) ([]GetPostsForUserByPublishedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByPublished,
+		arg.UserID,
+		arg.Feed,
+		arg.PublishedFrom,
+		arg.PublishedTo,
+		arg.Keyword,
+		arg.Read,
+		arg.CursorID,
+		arg.CursorTime,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserByPublishedRow
+	for rows.Next() {
+		var i GetPostsForUserByPublishedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+const getPostsForUserByPublishedAfter = `-- name: GetPostsForUserByPublishedAfter :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $1
+AND ($2::text IS NULL OR feeds.name = $2 OR feeds.url = $2)
+AND ($3::timestamp IS NULL OR posts.published_at >= $3)
+AND ($4::timestamp IS NULL OR posts.published_at < $4)
+AND ($5::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', $5))
+AND ($6::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = $6)
+AND ((posts.published_at, posts.id) > ($7::timestamp, $8::uuid)
+		OR ($7::timestamp IS NULL AND (posts.published_at IS NOT NULL OR posts.id > $8::uuid))
+)
+ORDER BY posts.published_at ASC NULLS FIRST, posts.id ASC
+LIMIT $9
+`
+
+type GetPostsForUserByPublishedAfterParams struct {
+	UserID        uuid.UUID
+	Feed          sql.NullString
+	PublishedFrom sql.NullTime
+	PublishedTo   sql.NullTime
+	Keyword       sql.NullString
+	Read          sql.NullBool
+	CursorTime    sql.NullTime
+	CursorID      uuid.UUID
+	PageSize      int32
+}
+
+type GetPostsForUserByPublishedAfterRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	CreatedAt   time.Time
+	FeedName    string
+	FeedUrl     string
+}
+
+func (q *Queries) GetPostsForUserByPublishedAfter(ctx context.Context, arg GetPostsForUserByPublishedAfterParams) ([]GetPostsForUserByPublishedAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByPublishedAfter,
+		arg.UserID,
+		arg.Feed,
+		arg.PublishedFrom,
+		arg.PublishedTo,
+		arg.Keyword,
+		arg.Read,
+		arg.CursorTime,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserByPublishedAfterRow
+	for rows.Next() {
+		var i GetPostsForUserByPublishedAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.FeedName,
+			&i.FeedUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchPostsForUser = `-- name: SearchPostsForUser :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, feeds.name AS feed_name,
+		ts_rank(posts.search_vector, websearch_to_tsquery('english', $1)) AS rank
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = $2
+AND posts.search_vector @@ websearch_to_tsquery('english', $1)
+AND ($3::text IS NULL OR feeds.name = $3 OR feeds.url = $3)
+AND ($4::timestamp IS NULL OR posts.published_at >= $4)
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT $5
+`
+
+type SearchPostsForUserParams struct {
+	Query       string
+	UserID      uuid.UUID
+	Feed        sql.NullString
+	Since       sql.NullTime
+	ResultLimit int32
+}
+
+type SearchPostsForUserRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedName    string
+	Rank        float32
+}
+
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg SearchPostsForUserParams) ([]SearchPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPostsForUser,
+		arg.Query,
+		arg.UserID,
+		arg.Feed,
+		arg.Since,
+		arg.ResultLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetPostsForUserRow
+	var items []SearchPostsForUserRow
 	for rows.Next() {
-		var i GetPostsForUserRow
+		var i SearchPostsForUserRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Title,
 			&i.Url,
 			&i.Description,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonFeedTitleLength is how much of an untitled item's text becomes its
+// title.
+const jsonFeedTitleLength = 80
+
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string               `json:"id"`
+	URL           string               `json:"url"`
+	ExternalURL   string               `json:"external_url"`
+	Title         string               `json:"title"`
+	ContentHTML   string               `json:"content_html"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary"`
+	DatePublished string               `json:"date_published"`
+	DateModified  string               `json:"date_modified"`
+	Attachments   []JSONFeedAttachment `json:"attachments"`
+}
+
+type JSONFeedAttachment struct {
+	URL         string `json:"url"`
+	MimeType    string `json:"mime_type"`
+	Title       string `json:"title"`
+	SizeInBytes int64  `json:"size_in_bytes"`
+}
+
+func decodeJSONFeed(data []byte) (*JSONFeed, error) {
+	var feed JSONFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(feed.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("unsupported JSON feed version: '%s'", feed.Version)
+	}
+	return &feed, nil
+}
+
+func (f *JSONFeed) normalize() *Feed {
+	feed := &Feed{
+		Title:       f.Title,
+		Link:        f.HomePageURL,
+		Description: f.Description,
+	}
+	for _, item := range f.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+		if link == "" {
+			link = item.ID
+		}
+		description := item.Summary
+		if description == "" {
+			description = item.ContentHTML
+		}
+		if description == "" {
+			description = item.ContentText
+		}
+		pubDate := item.DatePublished
+		if pubDate == "" {
+			pubDate = item.DateModified
+		}
+		title := item.Title
+		if title == "" {
+			// titles are optional, and microblog posts usually go without
+			text := item.ContentText
+			if text == "" {
+				text = item.Summary
+			}
+			if text == "" {
+				text = item.ContentHTML
+			}
+			title = shorten(plainText(text), jsonFeedTitleLength)
+		}
+		feedItem := FeedItem{
+			Title:       title,
+			Link:        link,
+			Description: description,
+			PubDate:     pubDate,
+		}
+		for _, attachment := range item.Attachments {
+			feedItem.Attachments = append(feedItem.Attachments, FeedAttachment{
+				URL:      attachment.URL,
+				MimeType: attachment.MimeType,
+				Length:   attachment.SizeInBytes,
+			})
+		}
+		feed.Items = append(feed.Items, feedItem)
+	}
+	return feed
+}
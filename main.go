@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	_ "github.com/lib/pq"
 
@@ -22,31 +27,267 @@ func main() {
 	var s state
 	s.cfg = &cfg
 	s.db = dbQueries
-	cmds := commands{
-		commands: make(map[string]func(*state, command) error),
-	}
-	cmds.register("login", handlerLogin)
-	cmds.register("register", handlerRegister)
-	cmds.register("reset", handlerReset)
-	cmds.register("users", handlerUsers)
-	cmds.register("agg", handlerAgg)
-	cmds.register("addfeed", middlewareLoggedIn(handlerAddfeed))
-	cmds.register("feeds", handlerFeeds)
-	cmds.register("follow", middlewareLoggedIn(handlerFollow))
-	cmds.register("following", middlewareLoggedIn(handlerFollowing))
-	cmds.register("unfollow", middlewareLoggedIn(handlerUnfollow))
-	cmds.register("browse", middlewareLoggedIn(handlerBrowse))
+	s.conn = db
+	s.metrics = newFetchMetrics()
+	s.fetcher, err = newFeedFetcher(cfg.Fetch)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmds := newCommands()
 
-	if len(os.Args) < 2 {
-		fmt.Println("error: not enough arguments")
-		os.Exit(1)
+	// global flags come before the command name
+	args := os.Args[1:]
+	format := "text"
+	for len(args) > 0 && strings.HasPrefix(args[0], "--output") {
+		if value, ok := strings.CutPrefix(args[0], "--output="); ok {
+			format = value
+			args = args[1:]
+		} else if args[0] == "--output" && len(args) > 1 {
+			format = args[1]
+			args = args[2:]
+		} else {
+			fmt.Fprintln(os.Stderr, "error: --output requires a format")
+			os.Exit(exitInvalidArgs)
+		}
+	}
+	s.out, err = newOutput(format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(exitCode(err))
 	}
+	if len(args) < 1 {
+		cmds.printHelp(os.Stderr)
+		os.Exit(exitInvalidArgs)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop() // a second interrupt kills gator right away
+	}()
 	cmd := command{
-		name: os.Args[1],
-		args: os.Args[2:],
+		ctx:  ctx,
+		name: args[0],
+		args: args[1:],
+	}
+	if cmd.name == "-h" || cmd.name == "--help" {
+		cmd.name = "help"
 	}
 	if err := cmds.run(&s, cmd); err != nil {
-		fmt.Println("error:", err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+func newCommands() *commands {
+	cmds := &commands{
+		commands: make(map[string]*commandSpec),
 	}
+	cmds.register(commandSpec{
+		name:    "help",
+		args:    "[command]",
+		maxArgs: 1,
+		summary: "list commands, or show how to use one",
+		handler: cmds.handlerHelp,
+	})
+	cmds.register(commandSpec{
+		name:    "login",
+		args:    "<username>",
+		minArgs: 1,
+		maxArgs: 1,
+		summary: "log in as a different, already existing user",
+		handler: handlerLogin,
+	})
+	cmds.register(commandSpec{
+		name:    "register",
+		args:    "<username>",
+		minArgs: 1,
+		maxArgs: 1,
+		summary: "create a user and log in as them",
+		handler: handlerRegister,
+	})
+	cmds.register(commandSpec{
+		name:    "reset",
+		summary: "reset the state of the program, clearing all stored data",
+		handler: handlerReset,
+	})
+	cmds.register(commandSpec{
+		name:    "users",
+		summary: "list existing users",
+		handler: handlerUsers,
+	})
+	cmds.register(commandSpec{
+		name:    "agg",
+		args:    "[<interval> [concurrency]]",
+		maxArgs: 2,
+		summary: "aggregate posts from followed feeds, checking for due feeds every interval, e.g. 'gator agg 1m 4'; the interval is optional with --once",
+		flags: func(f *flag.FlagSet) {
+			f.Bool("once", false, "fetch the feeds that are due once and exit, e.g. from cron")
+			f.String("metrics-addr", "", "serve Prometheus metrics at /metrics on this address, e.g. :9090")
+		},
+		handler: handlerAgg,
+	})
+	cmds.register(commandSpec{
+		name:    "addfeed",
+		args:    "<name> <url>",
+		minArgs: 2,
+		maxArgs: 2,
+		summary: "add a feed and follow it",
+		handler: middlewareLoggedIn(handlerAddfeed),
+	})
+	cmds.register(commandSpec{
+		name:    "feeds",
+		summary: "list added feeds",
+		handler: handlerFeeds,
+	})
+	cmds.register(commandSpec{
+		name:    "feed-status",
+		args:    "[enable <url>]",
+		maxArgs: 2,
+		summary: "show the health of every feed, or re-enable a feed disabled after repeated failures",
+		handler: handlerFeedStatus,
+	})
+	cmds.register(commandSpec{
+		name:    "follow",
+		args:    "<url>",
+		minArgs: 1,
+		maxArgs: 1,
+		summary: "follow a feed",
+		handler: middlewareLoggedIn(handlerFollow),
+	})
+	cmds.register(commandSpec{
+		name:    "following",
+		summary: "list followed feeds",
+		handler: middlewareLoggedIn(handlerFollowing),
+	})
+	cmds.register(commandSpec{
+		name:    "unfollow",
+		args:    "<url>",
+		minArgs: 1,
+		maxArgs: 1,
+		summary: "unfollow a feed",
+		handler: middlewareLoggedIn(handlerUnfollow),
+	})
+	cmds.register(commandSpec{
+		name:    "browse",
+		args:    "[page-size]",
+		maxArgs: 1,
+		summary: "browse posts from followed feeds, newest first, marking them as read",
+		flags: func(f *flag.FlagSet) {
+			f.Bool("unread", false, "only posts you haven't read")
+			f.Bool("read", false, "only posts you have read")
+			f.String("feed", "", "only posts from the feed with this name or url")
+			f.String("since", "", "only posts published since a duration ago (24h, 7d) or a date")
+			f.String("from", "", "same as --since")
+			f.String("to", "", "only posts published before a duration ago or up to a date")
+			f.String("keyword", "", "only posts matching these words")
+			f.String("sort", "published", "order by published, ingested or feed")
+			f.Int("page-size", 2, "number of posts to show")
+			f.String("before", "", "continue from a printed next cursor")
+			f.String("after", "", "page towards newer posts from a cursor")
+		},
+		handler: middlewareLoggedIn(handlerBrowse),
+	})
+	cmds.register(commandSpec{
+		name:    "read",
+		args:    "<post-id>",
+		minArgs: 1,
+		maxArgs: 1,
+		summary: "mark a post as read",
+		handler: middlewareLoggedIn(handlerRead),
+	})
+	cmds.register(commandSpec{
+		name:    "unread",
+		summary: "count unread posts per followed feed",
+		handler: middlewareLoggedIn(handlerUnread),
+	})
+	cmds.register(commandSpec{
+		name:    "save",
+		args:    "<post-id>",
+		minArgs: 1,
+		maxArgs: 1,
+		summary: "bookmark a post",
+		handler: middlewareLoggedIn(handlerSave),
+	})
+	cmds.register(commandSpec{
+		name:    "unsave",
+		args:    "<post-id>",
+		minArgs: 1,
+		maxArgs: 1,
+		summary: "remove a bookmark",
+		handler: middlewareLoggedIn(handlerUnsave),
+	})
+	cmds.register(commandSpec{
+		name:    "saved",
+		summary: "list bookmarked posts",
+		handler: middlewareLoggedIn(handlerSaved),
+	})
+	cmds.register(commandSpec{
+		name:    "attachments",
+		args:    "<post-id>",
+		minArgs: 1,
+		maxArgs: 1,
+		summary: "list the enclosures and attachments of a post, such as podcast audio",
+		handler: handlerAttachments,
+	})
+	cmds.register(commandSpec{
+		name:    "search",
+		args:    "<query>...",
+		minArgs: 1,
+		maxArgs: -1,
+		summary: "full-text search over posts from followed feeds, best matches first",
+		flags: func(f *flag.FlagSet) {
+			f.String("feed", "", "only posts from the feed with this name or url")
+			f.String("since", "", "only posts published since a duration ago (24h, 7d) or a date")
+			f.Int("limit", 10, "maximum number of results")
+		},
+		handler: middlewareLoggedIn(handlerSearch),
+	})
+	cmds.register(commandSpec{
+		name:    "import",
+		args:    "<file.opml>",
+		minArgs: 1,
+		maxArgs: 1,
+		summary: "follow every feed listed in an OPML file",
+		handler: middlewareLoggedIn(handlerImport),
+	})
+	cmds.register(commandSpec{
+		name:    "export",
+		summary: "write the feeds you follow as an OPML document",
+		flags: func(f *flag.FlagSet) {
+			f.String("output", "", "file to write instead of stdout")
+		},
+		handler: middlewareLoggedIn(handlerExport),
+	})
+	cmds.register(commandSpec{
+		name:    "serve",
+		summary: "serve the JSON API and the web reader",
+		flags: func(f *flag.FlagSet) {
+			f.String("addr", ":8080", "address to listen on")
+		},
+		handler: handlerServe,
+	})
+	cmds.register(commandSpec{
+		name:    "token",
+		args:    "<create <name> | list | revoke <token-id>>",
+		minArgs: 1,
+		maxArgs: -1,
+		summary: "manage API tokens",
+		handler: middlewareLoggedIn(handlerToken),
+	})
+	cmds.register(commandSpec{
+		name:    "render-feed",
+		summary: "write your merged timeline as an RSS or Atom feed",
+		flags: func(f *flag.FlagSet) {
+			f.String("format", "rss", "rss or atom")
+			f.Int("limit", defaultTimelineLimit, "number of posts")
+		},
+		handler: middlewareLoggedIn(handlerRenderFeed),
+	})
+	cmds.register(commandSpec{
+		name:    "tui",
+		summary: "full-screen terminal reader",
+		handler: middlewareLoggedIn(handlerTui),
+	})
+	return cmds
 }
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// feedFetch describes one pass of scrapeFeed over a feed, for logging and
+// metrics.
+type feedFetch struct {
+	url        string
+	duration   time.Duration
+	statusCode int // 0 when the server never responded
+	bytes      int
+	items      int
+	inserted   int
+	duplicates int
+	err        error
+}
+
+type feedCounters struct {
+	fetches         map[string]int64 // by status code, or "error" without a response
+	durationSeconds float64
+	bytes           int64
+	items           int64
+	inserted        int64
+	duplicates      int64
+}
+
+// fetchMetrics accumulates feedFetch results per feed url and writes them
+// in the Prometheus text exposition format.
+type fetchMetrics struct {
+	mu    sync.Mutex
+	feeds map[string]*feedCounters
+}
+
+func newFetchMetrics() *fetchMetrics {
+	return &fetchMetrics{feeds: make(map[string]*feedCounters)}
+}
+
+func (m *fetchMetrics) record(fetch feedFetch) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counters, ok := m.feeds[fetch.url]
+	if !ok {
+		counters = &feedCounters{fetches: make(map[string]int64)}
+		m.feeds[fetch.url] = counters
+	}
+	status := "error"
+	if fetch.statusCode != 0 {
+		status = strconv.Itoa(fetch.statusCode)
+	}
+	counters.fetches[status]++
+	counters.durationSeconds += fetch.duration.Seconds()
+	counters.bytes += int64(fetch.bytes)
+	counters.items += int64(fetch.items)
+	counters.inserted += int64(fetch.inserted)
+	counters.duplicates += int64(fetch.duplicates)
+}
+
+func (m *fetchMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	urls := make([]string, 0, len(m.feeds))
+	for url := range m.feeds {
+		urls = append(urls, url)
+	}
+	slices.Sort(urls)
+
+	metric := func(name, kind, help string, value func(url string, c *feedCounters)) {
+		fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, kind)
+		for _, url := range urls {
+			value(url, m.feeds[url])
+		}
+	}
+	counter := func(name, help string, value func(c *feedCounters) int64) {
+		metric(name, "counter", help, func(url string, c *feedCounters) {
+			fmt.Fprintf(w, "%v{feed=%v} %d\n", name, labelValue(url), value(c))
+		})
+	}
+
+	metric("gator_feed_fetches_total", "counter", "Feed fetches by HTTP status, or error when the server never responded.", func(url string, c *feedCounters) {
+		statuses := make([]string, 0, len(c.fetches))
+		for status := range c.fetches {
+			statuses = append(statuses, status)
+		}
+		slices.Sort(statuses)
+		for _, status := range statuses {
+			fmt.Fprintf(w, "gator_feed_fetches_total{feed=%v,status=%v} %d\n", labelValue(url), labelValue(status), c.fetches[status])
+		}
+	})
+	metric("gator_feed_fetch_duration_seconds", "summary", "Time spent fetching and parsing feeds.", func(url string, c *feedCounters) {
+		var total int64
+		for _, n := range c.fetches {
+			total += n
+		}
+		fmt.Fprintf(w, "gator_feed_fetch_duration_seconds_sum{feed=%v} %v\n", labelValue(url), strconv.FormatFloat(c.durationSeconds, 'f', -1, 64))
+		fmt.Fprintf(w, "gator_feed_fetch_duration_seconds_count{feed=%v} %d\n", labelValue(url), total)
+	})
+	counter("gator_feed_response_bytes_total", "Bytes of feed documents downloaded.", func(c *feedCounters) int64 { return c.bytes })
+	counter("gator_feed_items_parsed_total", "Items found in fetched feeds.", func(c *feedCounters) int64 { return c.items })
+	counter("gator_feed_posts_inserted_total", "Items stored as new posts.", func(c *feedCounters) int64 { return c.inserted })
+	counter("gator_feed_duplicates_skipped_total", "Items skipped because their post was already stored.", func(c *feedCounters) int64 { return c.duplicates })
+}
+
+func (m *fetchMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.write(w)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelValue(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
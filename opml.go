@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/michalronin/gator/internal/database"
+)
+
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title,omitempty"`
+		DateCreated string `xml:"dateCreated,omitempty"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []OPMLOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+type OPMLOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []OPMLOutline `xml:"outline"`
+}
+
+type importSummary struct {
+	Created  int `json:"created"`
+	Followed int `json:"followed"`
+	Skipped  int `json:"skipped"`
+	Invalid  int `json:"invalid"`
+}
+
+func handlerImport(s *state, cmd command, user database.User) error {
+	data, err := os.ReadFile(cmd.args[0])
+	if err != nil {
+		return err
+	}
+	var opml OPML
+	if err := xml.Unmarshal(data, &opml); err != nil {
+		return invalidArgsError("could not parse OPML file: %v", err)
+	}
+
+	tx, err := s.conn.BeginTx(cmd.ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	importer := opmlImporter{
+		ctx:  cmd.ctx,
+		db:   s.db.WithTx(tx),
+		user: user,
+		seen: make(map[string]bool),
+	}
+	if err := importer.importOutlines(opml.Body.Outlines, ""); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return renderItem(s.out, importer.summary, func(summary importSummary) {
+		fmt.Printf("import finished: %d created, %d followed, %d skipped, %d invalid\n", summary.Created, summary.Followed, summary.Skipped, summary.Invalid)
+	})
+}
+
+type opmlImporter struct {
+	ctx     context.Context
+	db      *database.Queries
+	user    database.User
+	seen    map[string]bool
+	summary importSummary
+}
+
+// importOutlines walks the outline tree; outlines without an xmlUrl are
+// folders, and their path is stored on the follows created beneath them.
+func (i *opmlImporter) importOutlines(outlines []OPMLOutline, folder string) error {
+	for _, outline := range outlines {
+		if outline.XMLURL == "" {
+			if len(outline.Outlines) == 0 {
+				i.summary.Invalid++
+				continue
+			}
+			if err := i.importOutlines(outline.Outlines, joinFolder(folder, outline.name())); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := i.importFeed(outline, folder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *opmlImporter) importFeed(outline OPMLOutline, folder string) error {
+	feedURL := strings.TrimSpace(outline.XMLURL)
+	if !validFeedURL(feedURL) {
+		i.summary.Invalid++
+		return nil
+	}
+	if i.seen[feedURL] {
+		i.summary.Skipped++
+		return nil
+	}
+	i.seen[feedURL] = true
+
+	created := false
+	feed, err := i.db.GetFeedByUrl(i.ctx, feedURL)
+	if err == sql.ErrNoRows {
+		name := outline.name()
+		if name == "" {
+			name = feedURL
+		}
+		feed, err = i.db.CreateFeed(i.ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+			Name:      name,
+			Url:       feedURL,
+			UserID:    i.user.ID,
+		})
+		created = true
+	}
+	if err != nil {
+		return err
+	}
+	if created && outline.HTMLURL != "" {
+		if err := i.db.SetFeedSiteUrl(i.ctx, database.SetFeedSiteUrlParams{
+			SiteUrl: sql.NullString{String: outline.HTMLURL, Valid: true},
+			ID:      feed.ID,
+		}); err != nil {
+			return err
+		}
+	}
+	if !created {
+		following, err := i.db.IsFollowingFeed(i.ctx, database.IsFollowingFeedParams{
+			UserID: i.user.ID,
+			FeedID: feed.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if following {
+			i.summary.Skipped++
+			return nil
+		}
+	}
+	feedFollow, err := i.db.CreateFeedFollow(i.ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    i.user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if folder != "" {
+		if err := i.db.SetFeedFollowFolder(i.ctx, database.SetFeedFollowFolderParams{
+			Folder:    sql.NullString{String: folder, Valid: true},
+			UpdatedAt: time.Now(),
+			ID:        feedFollow.ID,
+		}); err != nil {
+			return err
+		}
+	}
+	if created {
+		i.summary.Created++
+	} else {
+		i.summary.Followed++
+	}
+	return nil
+}
+
+func handlerExport(s *state, cmd command, user database.User) error {
+	output := cmd.stringFlag("output")
+	feedsFollowed, err := s.db.GetFeedFollowsForUser(cmd.ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	var opml OPML
+	opml.Version = "2.0"
+	opml.Head.Title = fmt.Sprintf("gator subscriptions of %v", user.Name)
+	opml.Head.DateCreated = time.Now().Format(time.RFC1123Z)
+	root := &OPMLOutline{}
+	for _, feed := range feedsFollowed {
+		parent := root
+		if feed.Folder.Valid && feed.Folder.String != "" {
+			for _, name := range strings.Split(feed.Folder.String, "/") {
+				parent = parent.folder(name)
+			}
+		}
+		parent.Outlines = append(parent.Outlines, OPMLOutline{
+			Text:    feed.FeedName,
+			Title:   feed.FeedName,
+			Type:    "rss",
+			XMLURL:  feed.FeedUrl,
+			HTMLURL: feed.FeedSiteUrl.String,
+		})
+	}
+	opml.Body.Outlines = root.Outlines
+
+	data, err := xml.MarshalIndent(opml, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+	if output == "" {
+		if err := s.out.requireText("export writes OPML unless given a file"); err != nil {
+			return err
+		}
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return err
+	}
+	return s.out.message("exported %d feeds to %v", len(feedsFollowed), output)
+}
+
+// folder returns the child folder outline with the given name, creating it
+// if needed.
+func (o *OPMLOutline) folder(name string) *OPMLOutline {
+	for i := range o.Outlines {
+		if o.Outlines[i].XMLURL == "" && o.Outlines[i].Text == name {
+			return &o.Outlines[i]
+		}
+	}
+	o.Outlines = append(o.Outlines, OPMLOutline{Text: name, Title: name})
+	return &o.Outlines[len(o.Outlines)-1]
+}
+
+func (o OPMLOutline) name() string {
+	if o.Title != "" {
+		return o.Title
+	}
+	return o.Text
+}
+
+func joinFolder(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "/" + name
+}
+
+func validFeedURL(feedURL string) bool {
+	parsed, err := url.Parse(feedURL)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
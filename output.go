@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var outputFormats = []string{"text", "json", "jsonl", "csv", "table"}
+
+// output writes command results in the format picked with the global
+// --output flag. Handlers describe their results as records, structs whose
+// json tags also name the csv and table columns, and keep printing the text
+// format themselves.
+type output struct {
+	format string
+	w      io.Writer
+}
+
+func newOutput(format string) (*output, error) {
+	if !slices.Contains(outputFormats, format) {
+		return nil, invalidArgsError("output must be one of %v", strings.Join(outputFormats, ", "))
+	}
+	return &output{format: format, w: os.Stdout}, nil
+}
+
+// renderList writes records; text prints one record in the text format.
+func renderList[T any](out *output, records []T, text func(T)) error {
+	switch out.format {
+	case "text":
+		for _, record := range records {
+			text(record)
+		}
+		return nil
+	case "json":
+		if records == nil {
+			records = []T{}
+		}
+		encoder := json.NewEncoder(out.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case "jsonl":
+		encoder := json.NewEncoder(out.w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	columns := recordColumns(reflect.TypeOf((*T)(nil)).Elem())
+	var rows [][]string
+	for _, record := range records {
+		rows = append(rows, recordCells(reflect.ValueOf(record)))
+	}
+	if out.format == "csv" {
+		writer := csv.NewWriter(out.w)
+		writer.Write(columns)
+		return writer.WriteAll(rows)
+	}
+	writer := tabwriter.NewWriter(out.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = strings.Join(strings.Fields(cell), " ") // keep each record on one line
+		}
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// renderItem writes a single record; json prints it as an object rather
+// than a one-element array.
+func renderItem[T any](out *output, record T, text func(T)) error {
+	if out.format == "json" {
+		encoder := json.NewEncoder(out.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(record)
+	}
+	return renderList(out, []T{record}, text)
+}
+
+type outputMessage struct {
+	Message string `json:"message"`
+}
+
+// message writes a confirmation such as "post saved".
+func (out *output) message(format string, a ...any) error {
+	return renderItem(out, outputMessage{Message: fmt.Sprintf(format, a...)}, func(m outputMessage) {
+		fmt.Println(m.Message)
+	})
+}
+
+// requireText rejects the structured formats for commands whose output is a
+// document or an interactive screen.
+func (out *output) requireText(reason string) error {
+	if out.format != "text" {
+		return invalidArgsError("--output %v is not supported: %v", out.format, reason)
+	}
+	return nil
+}
+
+// recordColumns lists the json names of a record's fields, flattening
+// embedded structs the way encoding/json does.
+func recordColumns(t reflect.Type) []string {
+	var columns []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			columns = append(columns, recordColumns(field.Type)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+func recordCells(v reflect.Value) []string {
+	var cells []string
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			cells = append(cells, recordCells(v.Field(i))...)
+			continue
+		}
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		cells = append(cells, cellString(v.Field(i)))
+	}
+	return cells
+}
+
+func cellString(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+	if list, ok := v.Interface().([]string); ok {
+		return strings.Join(list, "; ")
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -1,53 +1,80 @@
 package main
 
 import (
-	"context"
 	"encoding/xml"
-	"html"
-	"io"
-	"net/http"
+	"strings"
+	"time"
 )
 
 type RSSFeed struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr,omitempty"`
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Title       string        `xml:"title"`
+		Link        string        `xml:"link"`
+		Description string        `xml:"description"`
+		TTL         int           `xml:"ttl,omitempty"`
+		SkipHours   *RSSSkipHours `xml:"skipHours,omitempty"`
+		SkipDays    *RSSSkipDays  `xml:"skipDays,omitempty"`
+		Item        []RSSItem     `xml:"item"`
 	} `xml:"channel"`
 }
 
+type RSSSkipHours struct {
+	Hour []int `xml:"hour"`
+}
+
+type RSSSkipDays struct {
+	Day []string `xml:"day"`
+}
+
 type RSSItem struct {
 	Title       string `xml:"title"`
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	PubDate     string `xml:"pubDate,omitempty"`
+	GUID        string `xml:"guid,omitempty"`
+	Enclosure   []struct {
+		URL    string `xml:"url,attr"`
+		Type   string `xml:"type,attr"`
+		Length int64  `xml:"length,attr"`
+	} `xml:"enclosure"`
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("User-Agent", "gator")
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
+func (f *RSSFeed) normalize() *Feed {
+	feed := &Feed{
+		Title:       f.Channel.Title,
+		Link:        f.Channel.Link,
+		Description: f.Channel.Description,
+		TTL:         time.Duration(f.Channel.TTL) * time.Minute,
 	}
-	defer res.Body.Close()
-	data, err := io.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
+	if f.Channel.SkipHours != nil {
+		feed.SkipHours = f.Channel.SkipHours.Hour
 	}
-	var feed *RSSFeed
-	if err := xml.Unmarshal(data, &feed); err != nil {
-		return nil, err
+	if f.Channel.SkipDays != nil {
+		for _, day := range f.Channel.SkipDays.Day {
+			for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+				if strings.EqualFold(strings.TrimSpace(day), weekday.String()) {
+					feed.SkipDays = append(feed.SkipDays, weekday)
+				}
+			}
+		}
 	}
-	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
-	feed.Channel.Description = html.UnescapeString(feed.Channel.Description)
-	for i := range feed.Channel.Item {
-		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
-		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+	for _, item := range f.Channel.Item {
+		feedItem := FeedItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			PubDate:     item.PubDate,
+		}
+		for _, enclosure := range item.Enclosure {
+			feedItem.Attachments = append(feedItem.Attachments, FeedAttachment{
+				URL:      enclosure.URL,
+				MimeType: enclosure.Type,
+				Length:   enclosure.Length,
+			})
+		}
+		feed.Items = append(feed.Items, feedItem)
 	}
-	return feed, nil
+	return feed
 }
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultBaseInterval is the shortest polling interval when agg --once
+	// is run without one.
+	defaultBaseInterval = time.Minute
+	maxPollInterval     = 24 * time.Hour
+	// claimLease is how long a claimed feed stays hidden from other workers
+	// before it is scheduled properly, so a crashed agg doesn't lose it.
+	claimLease = 15 * time.Minute
+	// recentItems is how many of the newest items are used to estimate how
+	// often a feed publishes.
+	recentItems = 10
+	// feedFailureThreshold is how many fetches in a row may fail before a
+	// feed is disabled.
+	feedFailureThreshold = 10
+)
+
+// nextPollInterval adapts a feed's polling interval to how often it
+// publishes: twice per observed posting gap when item dates are available,
+// otherwise narrowing after new posts and widening after quiet fetches. The
+// result stays within [base, maxPollInterval] unless the publisher asked for
+// more through <ttl> or Cache-Control.
+func nextPollInterval(now time.Time, prev, base time.Duration, result *fetchResult, newPosts int) time.Duration {
+	interval := prev
+	if interval <= 0 {
+		interval = base
+	}
+	var feed *Feed
+	if result != nil {
+		feed = result.feed
+	}
+	if gap := postingGap(now, feed); gap > 0 {
+		interval = gap / 2
+	} else if newPosts > 0 {
+		interval /= 2
+	} else {
+		interval = interval * 3 / 2
+	}
+	interval = min(max(interval, base), maxPollInterval)
+	if feed != nil && feed.TTL > interval {
+		interval = feed.TTL
+	}
+	if result != nil && result.maxAge > interval {
+		interval = result.maxAge
+	}
+	return interval
+}
+
+// failureBackoff doubles a failing feed's interval for every consecutive
+// failure after the first.
+func failureBackoff(interval time.Duration, failures int32) time.Duration {
+	backoff := interval
+	for i := int32(1); i < failures && backoff < maxPollInterval; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxPollInterval)
+}
+
+// postingGap estimates the time between posts from the newest items' dates.
+// A feed that has gone quiet since its last post is treated as posting no
+// more often than that silence.
+func postingGap(now time.Time, feed *Feed) time.Duration {
+	if feed == nil {
+		return 0
+	}
+	var dates []time.Time
+	for _, item := range feed.Items {
+		if publishedAt, err := parseTime(item.PubDate); err == nil {
+			dates = append(dates, publishedAt)
+		}
+	}
+	if len(dates) < 2 {
+		return 0
+	}
+	slices.SortFunc(dates, func(a, b time.Time) int { return b.Compare(a) })
+	dates = dates[:min(len(dates), recentItems)]
+	gap := dates[0].Sub(dates[len(dates)-1]) / time.Duration(len(dates)-1)
+	if silence := now.Sub(dates[0]); silence > gap {
+		gap = silence
+	}
+	return gap
+}
+
+// nextFetchTime moves a scheduled fetch out of the hours (GMT) and days the
+// publisher listed in <skipHours> and <skipDays>.
+func nextFetchTime(now time.Time, interval time.Duration, feed *Feed) time.Time {
+	next := now.Add(interval)
+	if feed == nil {
+		return next
+	}
+	for i := 0; i < 7*24 && skipped(next.UTC(), feed); i++ {
+		next = next.UTC().Truncate(time.Hour).Add(time.Hour)
+	}
+	return next
+}
+
+func skipped(t time.Time, feed *Feed) bool {
+	return slices.Contains(feed.SkipHours, t.Hour()) || slices.Contains(feed.SkipDays, t.Weekday())
+}
+
+func parseMaxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		seconds, err := strconv.Atoi(strings.Trim(value, `"`))
+		if err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
+
+// parseRetryAfter accepts both forms of Retry-After: delay seconds and an
+// HTTP date.
+func parseRetryAfter(retryAfter string, now time.Time) (time.Duration, bool) {
+	if retryAfter == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// scheduleNow is a Monday, so day and hour skips are easy to follow.
+var scheduleNow = time.Date(2024, time.May, 6, 12, 0, 0, 0, time.UTC)
+
+// datedFeed returns a feed with one item published at each of the given
+// offsets before scheduleNow.
+func datedFeed(ago ...time.Duration) *Feed {
+	feed := &Feed{}
+	for _, d := range ago {
+		feed.Items = append(feed.Items, FeedItem{PubDate: scheduleNow.Add(-d).Format(time.RFC3339)})
+	}
+	return feed
+}
+
+func TestNextPollInterval(t *testing.T) {
+	const base = 10 * time.Minute
+	tests := []struct {
+		name     string
+		prev     time.Duration
+		result   *fetchResult
+		newPosts int
+		want     time.Duration
+	}{
+		{name: "first fetch without result widens from base", want: 15 * time.Minute},
+		{name: "new posts narrow", prev: time.Hour, result: &fetchResult{feed: &Feed{}}, newPosts: 2, want: 30 * time.Minute},
+		{name: "never below base", prev: 12 * time.Minute, result: &fetchResult{feed: &Feed{}}, newPosts: 1, want: base},
+		{name: "quiet fetch widens", prev: time.Hour, result: &fetchResult{feed: &Feed{}}, want: 90 * time.Minute},
+		{name: "never above max", prev: 20 * time.Hour, result: &fetchResult{feed: &Feed{}}, want: maxPollInterval},
+		{name: "half the posting gap", prev: time.Hour, result: &fetchResult{feed: datedFeed(time.Hour, 2*time.Hour, 3*time.Hour)}, want: 30 * time.Minute},
+		{name: "silence counts as the gap", prev: time.Hour, result: &fetchResult{feed: datedFeed(10*time.Hour, 11*time.Hour)}, want: 5 * time.Hour},
+		{name: "long silence is capped", prev: time.Hour, result: &fetchResult{feed: datedFeed(72*time.Hour, 73*time.Hour)}, want: maxPollInterval},
+		{name: "larger ttl wins", prev: time.Hour, result: &fetchResult{feed: &Feed{TTL: 3 * time.Hour}}, want: 3 * time.Hour},
+		{name: "ttl beyond max wins", prev: time.Hour, result: &fetchResult{feed: &Feed{TTL: 48 * time.Hour}}, want: 48 * time.Hour},
+		{name: "smaller ttl is ignored", prev: time.Hour, result: &fetchResult{feed: &Feed{TTL: time.Minute}}, want: 90 * time.Minute},
+		{name: "larger max-age wins", prev: time.Hour, result: &fetchResult{maxAge: 4 * time.Hour}, want: 4 * time.Hour},
+		{name: "smaller max-age is ignored", prev: time.Hour, result: &fetchResult{maxAge: time.Minute}, want: 90 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextPollInterval(scheduleNow, tt.prev, base, tt.result, tt.newPosts)
+			if got != tt.want {
+				t.Errorf("nextPollInterval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextFetchTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		feed     *Feed
+		want     time.Time
+	}{
+		{name: "no feed", interval: time.Hour, want: scheduleNow.Add(time.Hour)},
+		{name: "nothing skipped", interval: 30 * time.Minute, feed: &Feed{SkipHours: []int{13, 14}}, want: scheduleNow.Add(30 * time.Minute)},
+		{name: "skipped hours", interval: 90 * time.Minute, feed: &Feed{SkipHours: []int{13, 14}}, want: time.Date(2024, time.May, 6, 15, 0, 0, 0, time.UTC)},
+		{name: "skipped day", interval: 12 * time.Hour, feed: &Feed{SkipDays: []time.Weekday{time.Tuesday}}, want: time.Date(2024, time.May, 8, 0, 0, 0, 0, time.UTC)},
+		{
+			name: "skipped hours on the next day", interval: 11 * time.Hour,
+			feed: &Feed{SkipHours: []int{0, 1}, SkipDays: []time.Weekday{time.Monday}},
+			want: time.Date(2024, time.May, 7, 2, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextFetchTime(scheduleNow, tt.interval, tt.feed)
+			if !got.Equal(tt.want) {
+				t.Errorf("nextFetchTime = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMaxAge(t *testing.T) {
+	tests := []struct {
+		cacheControl string
+		want         time.Duration
+	}{
+		{"", 0},
+		{"no-cache", 0},
+		{"public, max-age=3600", time.Hour},
+		{"MAX-AGE=60", time.Minute},
+		{`max-age="120"`, 2 * time.Minute},
+		{"s-maxage=100, max-age=5", 5 * time.Second},
+		{"max-age=0", 0},
+		{"max-age=-10", 0},
+		{"max-age=soon", 0},
+	}
+	for _, tt := range tests {
+		if got := parseMaxAge(tt.cacheControl); got != tt.want {
+			t.Errorf("parseMaxAge(%q) = %v, want %v", tt.cacheControl, got, tt.want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		retryAfter string
+		want       time.Duration
+		wantOK     bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{scheduleNow.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{scheduleNow.Add(-time.Hour).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.retryAfter, scheduleNow)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.retryAfter, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestFailureBackoff(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		failures int32
+		want     time.Duration
+	}{
+		{time.Hour, 0, time.Hour},
+		{time.Hour, 1, time.Hour},
+		{time.Hour, 2, 2 * time.Hour},
+		{time.Hour, 4, 8 * time.Hour},
+		{time.Hour, 6, maxPollInterval},
+		{time.Hour, 1000, maxPollInterval},
+		{48 * time.Hour, 1, maxPollInterval},
+	}
+	for _, tt := range tests {
+		if got := failureBackoff(tt.interval, tt.failures); got != tt.want {
+			t.Errorf("failureBackoff(%v, %d) = %v, want %v", tt.interval, tt.failures, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,479 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/michalronin/gator/internal/database"
+)
+
+type apiUser struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+}
+
+type apiFeed struct {
+	ID        *uuid.UUID `json:"id,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Name      string     `json:"name"`
+	Url       string     `json:"url"`
+	CreatedBy string     `json:"created_by,omitempty"`
+}
+
+type apiFeedFollow struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	FeedID    uuid.UUID `json:"feed_id"`
+	FeedName  string    `json:"feed_name"`
+	FeedUrl   string    `json:"feed_url,omitempty"`
+	Folder    string    `json:"folder,omitempty"`
+}
+
+type apiPost struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	Url         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	FeedName    string     `json:"feed_name,omitempty"`
+}
+
+type apiServer struct {
+	s *state
+}
+
+func handlerServe(s *state, cmd command) error {
+	addr := cmd.stringFlag("addr")
+	api := &apiServer{s: s}
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           api.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := s.out.message("Serving the gator API and web reader on %v", addr); err != nil {
+		return err
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	select {
+	case err := <-serveErr:
+		return err
+	case <-cmd.ctx.Done():
+		// let requests in progress finish before exiting
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(cmd.ctx), 10*time.Second)
+		defer cancel()
+		return server.Shutdown(ctx)
+	}
+}
+
+func (api *apiServer) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/users", api.authenticated(api.handleUsersList))
+	mux.HandleFunc("POST /v1/users", api.authenticated(api.handleUsersCreate))
+	mux.HandleFunc("GET /v1/feeds", api.authenticated(api.handleFeedsList))
+	mux.HandleFunc("POST /v1/feeds", api.authenticated(api.handleFeedsCreate))
+	mux.HandleFunc("GET /v1/feed_follows", api.authenticated(api.handleFeedFollowsList))
+	mux.HandleFunc("POST /v1/feed_follows", api.authenticated(api.handleFeedFollowsCreate))
+	mux.HandleFunc("DELETE /v1/feed_follows", api.authenticated(api.handleFeedFollowsDelete))
+	mux.HandleFunc("GET /v1/posts", api.authenticated(api.handlePostsList))
+	mux.HandleFunc("GET /v1/search", api.authenticated(api.handleSearch))
+	mux.HandleFunc("GET /v1/timeline.rss", tokenFromQuery(api.authenticated(api.handleTimeline("rss"))))
+	mux.HandleFunc("GET /v1/timeline.atom", tokenFromQuery(api.authenticated(api.handleTimeline("atom"))))
+	api.webRoutes(mux)
+	return mux
+}
+
+// authenticated is the API counterpart of middlewareLoggedIn, resolving the
+// user from an "Authorization: Bearer <token>" header.
+func (api *apiServer) authenticated(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			respondWithError(w, http.StatusUnauthorized, "bearer token required")
+			return
+		}
+		user, err := api.s.db.GetUserByApiToken(r.Context(), database.GetUserByApiTokenParams{
+			LastUsedAt: sql.NullTime{Time: time.Now(), Valid: true},
+			TokenHash:  hashToken(strings.TrimSpace(token)),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusUnauthorized, "invalid or revoked token")
+			return
+		}
+		if err != nil {
+			respondWithDBError(w, err)
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+// tokenFromQuery lets feed readers that can't send headers pass their token
+// as a "token" query parameter instead.
+func tokenFromQuery(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		handler(w, r)
+	}
+}
+
+func (api *apiServer) handleUsersList(w http.ResponseWriter, r *http.Request, user database.User) {
+	users, err := api.s.db.GetUsers(r.Context())
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	response := []apiUser{}
+	for _, user := range users {
+		response = append(response, apiUser(user))
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (api *apiServer) handleUsersCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	var params struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "name required")
+		return
+	}
+	created, err := api.s.db.CreateUser(r.Context(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      params.Name,
+	})
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, apiUser(created))
+}
+
+func (api *apiServer) handleFeedsList(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := api.s.db.GetFeeds(r.Context())
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	response := []apiFeed{}
+	for _, feed := range feeds {
+		response = append(response, apiFeed{
+			Name:      feed.Name,
+			Url:       feed.Url,
+			CreatedBy: feed.Username,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (api *apiServer) handleFeedsCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	var params struct {
+		Name string `json:"name"`
+		Url  string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Name == "" || params.Url == "" {
+		respondWithError(w, http.StatusBadRequest, "name and url required")
+		return
+	}
+	tx, err := api.s.conn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := api.s.db.WithTx(tx)
+	feed, err := qtx.CreateFeed(r.Context(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Name:      params.Name,
+		Url:       params.Url,
+		UserID:    user.ID,
+	})
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	if _, err := qtx.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	}); err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, apiFeed{
+		ID:        &feed.ID,
+		CreatedAt: &feed.CreatedAt,
+		Name:      feed.Name,
+		Url:       feed.Url,
+		CreatedBy: user.Name,
+	})
+}
+
+func (api *apiServer) handleFeedFollowsList(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedsFollowed, err := api.s.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	response := []apiFeedFollow{}
+	for _, feed := range feedsFollowed {
+		response = append(response, apiFeedFollow{
+			ID:        feed.ID,
+			CreatedAt: feed.CreatedAt,
+			FeedID:    feed.FeedID,
+			FeedName:  feed.FeedName,
+			FeedUrl:   feed.FeedUrl,
+			Folder:    feed.Folder.String,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (api *apiServer) handleFeedFollowsCreate(w http.ResponseWriter, r *http.Request, user database.User) {
+	var params struct {
+		Url string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Url == "" {
+		respondWithError(w, http.StatusBadRequest, "url required")
+		return
+	}
+	feedToFollow, err := api.s.db.GetFeedByUrl(r.Context(), params.Url)
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	feedFollow, err := api.s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feedToFollow.ID,
+	})
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, apiFeedFollow{
+		ID:        feedFollow.ID,
+		CreatedAt: feedFollow.CreatedAt,
+		FeedID:    feedFollow.FeedID,
+		FeedName:  feedFollow.FeedName,
+		FeedUrl:   feedToFollow.Url,
+	})
+}
+
+func (api *apiServer) handleFeedFollowsDelete(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedURL := r.URL.Query().Get("url")
+	if feedURL == "" {
+		respondWithError(w, http.StatusBadRequest, "url query parameter required")
+		return
+	}
+	removed, err := api.s.db.DeleteFeedFollow(r.Context(), database.DeleteFeedFollowParams{
+		UserID: user.ID,
+		Url:    feedURL,
+	})
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	if removed == 0 {
+		respondWithError(w, http.StatusNotFound, "not following "+feedURL)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (api *apiServer) handlePostsList(w http.ResponseWriter, r *http.Request, user database.User) {
+	limit, err := limitParam(r, 20)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := postQuery{
+		userID:   user.ID,
+		sort:     "published",
+		pageSize: limit,
+	}
+	if r.URL.Query().Get("unread") == "true" {
+		query.read = sql.NullBool{Bool: false, Valid: true}
+	}
+	posts, err := listPosts(r.Context(), api.s.db, query)
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	response := []apiPost{}
+	for _, post := range posts {
+		response = append(response, apiPost{
+			ID:          post.ID,
+			Title:       post.Title,
+			Url:         post.Url,
+			Description: post.Description.String,
+			PublishedAt: nullTimePtr(post.PublishedAt),
+			FeedName:    post.FeedName,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (api *apiServer) handleSearch(w http.ResponseWriter, r *http.Request, user database.User) {
+	query := r.URL.Query()
+	if query.Get("q") == "" {
+		respondWithError(w, http.StatusBadRequest, "q query parameter required")
+		return
+	}
+	limit, err := limitParam(r, 10)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := database.SearchPostsForUserParams{
+		Query:       query.Get("q"),
+		UserID:      user.ID,
+		ResultLimit: limit,
+	}
+	if feed := query.Get("feed"); feed != "" {
+		params.Feed = sql.NullString{String: feed, Valid: true}
+	}
+	if sinceParam := query.Get("since"); sinceParam != "" {
+		since, err := parseSince(sinceParam, time.Now())
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		params.Since = sql.NullTime{Time: since, Valid: true}
+	}
+	posts, err := api.s.db.SearchPostsForUser(r.Context(), params)
+	if err != nil {
+		respondWithDBError(w, err)
+		return
+	}
+	response := []apiPost{}
+	for _, post := range posts {
+		response = append(response, apiPost{
+			ID:          post.ID,
+			Title:       post.Title,
+			Url:         post.Url,
+			Description: post.Description.String,
+			PublishedAt: nullTimePtr(post.PublishedAt),
+			FeedName:    post.FeedName,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
+}
+
+func (api *apiServer) handleTimeline(format string) func(w http.ResponseWriter, r *http.Request, user database.User) {
+	contentTypes := map[string]string{
+		"rss":  "application/rss+xml; charset=utf-8",
+		"atom": "application/atom+xml; charset=utf-8",
+	}
+	return func(w http.ResponseWriter, r *http.Request, user database.User) {
+		limit, err := limitParam(r, defaultTimelineLimit)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		posts, err := listPosts(r.Context(), api.s.db, postQuery{
+			userID:   user.ID,
+			sort:     "published",
+			pageSize: limit,
+		})
+		if err != nil {
+			respondWithDBError(w, err)
+			return
+		}
+		selfURL := &url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path}
+		if r.TLS != nil {
+			selfURL.Scheme = "https"
+		}
+		data, err := renderTimeline(user, posts, format, selfURL.String())
+		if err != nil {
+			log.Println("api error:", err)
+			respondWithError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+		w.Header().Set("Content-Type", contentTypes[format])
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	}
+}
+
+func limitParam(r *http.Request, fallback int32) (int32, error) {
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr == "" {
+		return fallback, nil
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+	return int32(limit), nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func respondWithDBError(w http.ResponseWriter, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505": // unique violation
+			respondWithError(w, http.StatusConflict, "already exists")
+			return
+		case "23503": // foreign key violation
+			respondWithError(w, http.StatusNotFound, "not found")
+			return
+		}
+	}
+	log.Println("api error:", err)
+	respondWithError(w, http.StatusInternalServerError, "internal server error")
+}
+
+func respondWithError(w http.ResponseWriter, code int, msg string) {
+	respondWithJSON(w, code, map[string]string{"error": msg})
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Println("error marshalling JSON:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(data)
+}
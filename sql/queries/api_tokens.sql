@@ -0,0 +1,31 @@
+-- name: CreateApiToken :one
+INSERT INTO api_tokens (id, created_at, updated_at, user_id, name, token_hash)
+VALUES (
+		$1,
+		$2,
+		$3,
+		$4,
+		$5,
+		$6
+)
+RETURNING *;
+
+-- name: GetUserByApiToken :one
+WITH used_token AS (
+		UPDATE api_tokens
+		SET last_used_at = $1
+		WHERE token_hash = $2 AND revoked_at IS NULL
+		RETURNING user_id
+)
+SELECT users.* FROM users
+INNER JOIN used_token ON used_token.user_id = users.id;
+
+-- name: GetApiTokensForUser :many
+SELECT id, created_at, name, last_used_at, revoked_at FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: RevokeApiToken :execrows
+UPDATE api_tokens
+SET revoked_at = $1, updated_at = $1
+WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL;
@@ -18,13 +18,24 @@ INNER JOIN feeds ON feeds.id = inserted_feed_follow.feed_id
 INNER JOIN users ON users.id = inserted_feed_follow.user_id;
 
 -- name: GetFeedFollowsForUser :many
-SELECT feed_follows.*, users.name AS user_name, feeds.name AS feed_name FROM feed_follows
+SELECT feed_follows.*, users.name AS user_name, feeds.name AS feed_name, feeds.url AS feed_url, feeds.site_url AS feed_site_url FROM feed_follows
 INNER JOIN feeds ON feeds.id = feed_follows.feed_id
 INNER JOIN users ON users.id = feed_follows.user_id
 WHERE feed_follows.user_id = $1;
 
--- name: DeleteFeedFollow :exec
+-- name: DeleteFeedFollow :execrows
 DELETE FROM feed_follows
 WHERE feed_follows.user_id = $1 AND feed_id = (
 		SELECT id FROM feeds WHERE url = $2
 );
+
+-- name: IsFollowingFeed :one
+SELECT EXISTS (
+		SELECT 1 FROM feed_follows
+		WHERE user_id = $1 AND feed_id = $2
+);
+
+-- name: SetFeedFollowFolder :exec
+UPDATE feed_follows
+SET folder = $1, updated_at = $2
+WHERE id = $3;
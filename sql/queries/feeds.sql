@@ -17,12 +17,55 @@ INNER JOIN users ON feeds.user_id = users.id;
 -- name: GetFeedByUrl :one
 SELECT * FROM feeds WHERE url = $1;
 
--- name: MarkFeedFetched :exec
+-- name: ClaimNextFeedToFetch :one
 UPDATE feeds
-SET last_fetched_at = $1, updated_at = $1
+SET last_fetched_at = sqlc.arg(now), updated_at = sqlc.arg(now), next_fetch_at = sqlc.arg(lease_until)
+WHERE feeds.id = (
+		SELECT id FROM feeds
+		WHERE disabled_at IS NULL
+		AND (next_fetch_at IS NULL OR next_fetch_at <= sqlc.arg(now))
+		ORDER BY next_fetch_at NULLS FIRST
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+)
+RETURNING id, name, url, etag, last_modified, poll_interval_seconds;
+
+-- name: UpdateFeedCacheValidators :exec
+UPDATE feeds
+SET etag = $1, last_modified = $2
+WHERE feeds.id = $3;
+
+-- name: ScheduleFeedFetch :exec
+UPDATE feeds
+SET next_fetch_at = $1, poll_interval_seconds = $2
+WHERE feeds.id = $3;
+
+-- name: RecordFeedSuccess :exec
+UPDATE feeds
+SET consecutive_failures = 0, last_error = NULL, last_status_code = $1
 WHERE feeds.id = $2;
 
--- name: GetNextFeedToFetch :one
-SELECT id, name, url FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
-LIMIT 1;
+-- name: RecordFeedFailure :one
+UPDATE feeds
+SET consecutive_failures = consecutive_failures + 1,
+		last_error = sqlc.arg(last_error),
+		last_status_code = sqlc.arg(last_status_code),
+		disabled_at = CASE
+				WHEN consecutive_failures + 1 >= sqlc.arg(disable_after)::int THEN sqlc.arg(now)::timestamp
+		END
+WHERE feeds.id = sqlc.arg(id)
+RETURNING consecutive_failures, disabled_at;
+
+-- name: EnableFeed :execrows
+UPDATE feeds
+SET consecutive_failures = 0, disabled_at = NULL, next_fetch_at = NULL
+WHERE url = $1;
+
+-- name: GetFeedStatuses :many
+SELECT name, url, last_fetched_at, next_fetch_at, poll_interval_seconds, consecutive_failures, last_error, last_status_code, disabled_at FROM feeds
+ORDER BY name;
+
+-- name: SetFeedSiteUrl :exec
+UPDATE feeds
+SET site_url = $1
+WHERE feeds.id = $2;
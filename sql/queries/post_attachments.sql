@@ -0,0 +1,16 @@
+-- name: CreatePostAttachment :exec
+INSERT INTO post_attachments (id, created_at, post_id, url, mime_type, length)
+VALUES (
+		$1,
+		$2,
+		$3,
+		$4,
+		$5,
+		$6
+)
+ON CONFLICT (post_id, url) DO NOTHING;
+
+-- name: GetPostAttachments :many
+SELECT url, mime_type, length FROM post_attachments
+WHERE post_id = $1
+ORDER BY created_at, url;
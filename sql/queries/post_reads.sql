@@ -0,0 +1,21 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (id, created_at, updated_at, user_id, post_id)
+VALUES (
+		$1,
+		$2,
+		$3,
+		$4,
+		$5
+)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: CountUnreadPostsByFeed :many
+SELECT feeds.name, feeds.url, COUNT(posts.id) AS unread FROM feed_follows
+INNER JOIN feeds ON feeds.id = feed_follows.feed_id
+LEFT JOIN posts ON posts.feed_id = feeds.id AND NOT EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+)
+WHERE feed_follows.user_id = $1
+GROUP BY feeds.id, feeds.name, feeds.url
+ORDER BY feeds.name;
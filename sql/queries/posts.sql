@@ -11,9 +11,152 @@ VALUES (
 		$8
 );
 
--- name: GetPostsForUser :many
-SELECT posts.id, posts.title, posts.url, posts.description FROM posts 
+-- name: SearchPostsForUser :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, feeds.name AS feed_name,
+		ts_rank(posts.search_vector, websearch_to_tsquery('english', sqlc.arg(query))) AS rank
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
 INNER JOIN feeds ON feeds.id = posts.feed_id
-WHERE feeds.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2;
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND posts.search_vector @@ websearch_to_tsquery('english', sqlc.arg(query))
+AND (sqlc.narg(feed)::text IS NULL OR feeds.name = sqlc.narg(feed) OR feeds.url = sqlc.narg(feed))
+AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: GetPostPageForUser :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, feeds.name AS feed_name, feeds.url AS feed_url,
+		EXISTS (
+				SELECT 1 FROM post_reads
+				WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+		) AS read
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed_url)::text IS NULL OR feeds.url = sqlc.narg(feed_url))
+ORDER BY posts.published_at DESC NULLS LAST, posts.id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: GetPostUrl :one
+SELECT url FROM posts WHERE id = $1;
+
+-- name: GetPostsForUserByFeed :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed)::text IS NULL OR feeds.name = sqlc.narg(feed) OR feeds.url = sqlc.narg(feed))
+AND (sqlc.narg(published_from)::timestamp IS NULL OR posts.published_at >= sqlc.narg(published_from))
+AND (sqlc.narg(published_to)::timestamp IS NULL OR posts.published_at < sqlc.narg(published_to))
+AND (sqlc.narg(keyword)::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', sqlc.narg(keyword)))
+AND (sqlc.narg(read)::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = sqlc.narg(read))
+AND (sqlc.narg(cursor_id)::uuid IS NULL
+		OR feeds.name > sqlc.arg(cursor_feed)::text
+		OR (feeds.name = sqlc.arg(cursor_feed)::text AND (
+				(posts.published_at, posts.id) < (sqlc.narg(cursor_time)::timestamp, sqlc.narg(cursor_id)::uuid)
+				OR (posts.published_at IS NULL AND (sqlc.narg(cursor_time)::timestamp IS NOT NULL OR posts.id < sqlc.narg(cursor_id)::uuid))
+		))
+)
+ORDER BY feeds.name ASC, posts.published_at DESC NULLS LAST, posts.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetPostsForUserByFeedAfter :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed)::text IS NULL OR feeds.name = sqlc.narg(feed) OR feeds.url = sqlc.narg(feed))
+AND (sqlc.narg(published_from)::timestamp IS NULL OR posts.published_at >= sqlc.narg(published_from))
+AND (sqlc.narg(published_to)::timestamp IS NULL OR posts.published_at < sqlc.narg(published_to))
+AND (sqlc.narg(keyword)::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', sqlc.narg(keyword)))
+AND (sqlc.narg(read)::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = sqlc.narg(read))
+AND (feeds.name < sqlc.arg(cursor_feed)::text
+		OR (feeds.name = sqlc.arg(cursor_feed)::text AND (
+				(posts.published_at, posts.id) > (sqlc.narg(cursor_time)::timestamp, sqlc.arg(cursor_id)::uuid)
+				OR (sqlc.narg(cursor_time)::timestamp IS NULL AND (posts.published_at IS NOT NULL OR posts.id > sqlc.arg(cursor_id)::uuid))
+		))
+)
+ORDER BY feeds.name DESC, posts.published_at ASC NULLS FIRST, posts.id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetPostsForUserByIngested :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed)::text IS NULL OR feeds.name = sqlc.narg(feed) OR feeds.url = sqlc.narg(feed))
+AND (sqlc.narg(published_from)::timestamp IS NULL OR posts.published_at >= sqlc.narg(published_from))
+AND (sqlc.narg(published_to)::timestamp IS NULL OR posts.published_at < sqlc.narg(published_to))
+AND (sqlc.narg(keyword)::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', sqlc.narg(keyword)))
+AND (sqlc.narg(read)::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = sqlc.narg(read))
+AND (sqlc.narg(cursor_id)::uuid IS NULL
+		OR (posts.created_at, posts.id) < (sqlc.narg(cursor_time)::timestamp, sqlc.narg(cursor_id)::uuid)
+)
+ORDER BY posts.created_at DESC, posts.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetPostsForUserByIngestedAfter :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed)::text IS NULL OR feeds.name = sqlc.narg(feed) OR feeds.url = sqlc.narg(feed))
+AND (sqlc.narg(published_from)::timestamp IS NULL OR posts.published_at >= sqlc.narg(published_from))
+AND (sqlc.narg(published_to)::timestamp IS NULL OR posts.published_at < sqlc.narg(published_to))
+AND (sqlc.narg(keyword)::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', sqlc.narg(keyword)))
+AND (sqlc.narg(read)::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = sqlc.narg(read))
+AND (posts.created_at, posts.id) > (sqlc.arg(cursor_time)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY posts.created_at ASC, posts.id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetPostsForUserByPublished :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed)::text IS NULL OR feeds.name = sqlc.narg(feed) OR feeds.url = sqlc.narg(feed))
+AND (sqlc.narg(published_from)::timestamp IS NULL OR posts.published_at >= sqlc.narg(published_from))
+AND (sqlc.narg(published_to)::timestamp IS NULL OR posts.published_at < sqlc.narg(published_to))
+AND (sqlc.narg(keyword)::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', sqlc.narg(keyword)))
+AND (sqlc.narg(read)::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = sqlc.narg(read))
+AND (sqlc.narg(cursor_id)::uuid IS NULL
+		OR (posts.published_at, posts.id) < (sqlc.narg(cursor_time)::timestamp, sqlc.narg(cursor_id)::uuid)
+		OR (posts.published_at IS NULL AND (sqlc.narg(cursor_time)::timestamp IS NOT NULL OR posts.id < sqlc.narg(cursor_id)::uuid))
+)
+ORDER BY posts.published_at DESC NULLS LAST, posts.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetPostsForUserByPublishedAfter :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.published_at, posts.created_at, feeds.name AS feed_name, feeds.url AS feed_url FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+AND (sqlc.narg(feed)::text IS NULL OR feeds.name = sqlc.narg(feed) OR feeds.url = sqlc.narg(feed))
+AND (sqlc.narg(published_from)::timestamp IS NULL OR posts.published_at >= sqlc.narg(published_from))
+AND (sqlc.narg(published_to)::timestamp IS NULL OR posts.published_at < sqlc.narg(published_to))
+AND (sqlc.narg(keyword)::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', sqlc.narg(keyword)))
+AND (sqlc.narg(read)::boolean IS NULL OR EXISTS (
+		SELECT 1 FROM post_reads
+		WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+) = sqlc.narg(read))
+AND ((posts.published_at, posts.id) > (sqlc.narg(cursor_time)::timestamp, sqlc.arg(cursor_id)::uuid)
+		OR (sqlc.narg(cursor_time)::timestamp IS NULL AND (posts.published_at IS NOT NULL OR posts.id > sqlc.arg(cursor_id)::uuid))
+)
+ORDER BY posts.published_at ASC NULLS FIRST, posts.id ASC
+LIMIT sqlc.arg(page_size);
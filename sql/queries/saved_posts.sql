@@ -0,0 +1,20 @@
+-- name: SavePost :exec
+INSERT INTO saved_posts (id, created_at, updated_at, user_id, post_id)
+VALUES (
+		$1,
+		$2,
+		$3,
+		$4,
+		$5
+)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: UnsavePost :execrows
+DELETE FROM saved_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetSavedPostsForUser :many
+SELECT posts.id, posts.title, posts.url, posts.description, saved_posts.created_at AS saved_at FROM saved_posts
+INNER JOIN posts ON posts.id = saved_posts.post_id
+WHERE saved_posts.user_id = $1
+ORDER BY saved_posts.created_at DESC;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD next_fetch_at TIMESTAMP,
+ADD poll_interval_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN next_fetch_at,
+DROP COLUMN poll_interval_seconds;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD consecutive_failures INTEGER NOT NULL DEFAULT 0,
+ADD last_error TEXT,
+ADD last_status_code INTEGER,
+ADD disabled_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN consecutive_failures,
+DROP COLUMN last_error,
+DROP COLUMN last_status_code,
+DROP COLUMN disabled_at;
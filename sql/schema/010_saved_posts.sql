@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE saved_posts (
+		id UUID PRIMARY KEY,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		UNIQUE (user_id, post_id)
+);
+
+-- +goose Down
+DROP TABLE saved_posts;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE posts
+ADD search_vector tsvector GENERATED ALWAYS AS (
+		to_tsvector('english', title || ' ' || coalesce(description, ''))
+) STORED;
+CREATE INDEX posts_search_vector_idx ON posts USING GIN (search_vector);
+
+-- +goose Down
+DROP INDEX posts_search_vector_idx;
+ALTER TABLE posts
+DROP COLUMN search_vector;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD site_url TEXT;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN site_url;
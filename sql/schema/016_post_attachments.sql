@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE post_attachments (
+		id UUID PRIMARY KEY,
+		created_at TIMESTAMP NOT NULL,
+		post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+		url TEXT NOT NULL,
+		mime_type TEXT,
+		length BIGINT,
+		UNIQUE (post_id, url)
+);
+
+-- +goose Down
+DROP TABLE post_attachments;
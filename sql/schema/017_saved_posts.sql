@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE saved_posts
+DROP CONSTRAINT saved_posts_post_id_fkey,
+ADD CONSTRAINT saved_posts_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE RESTRICT;
+
+-- +goose Down
+ALTER TABLE saved_posts
+DROP CONSTRAINT saved_posts_post_id_fkey,
+ADD CONSTRAINT saved_posts_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE;
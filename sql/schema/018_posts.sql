@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX posts_created_at_id_idx ON posts (created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX posts_created_at_id_idx;
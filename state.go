@@ -1,11 +1,18 @@
 package main
 
 import (
+	"database/sql"
+
 	"github.com/michalronin/gator/internal/config"
 	"github.com/michalronin/gator/internal/database"
 )
 
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db   *database.Queries
+	conn *sql.DB
+	cfg  *config.Config
+	out  *output
+	// metrics counts what agg fetched, for its /metrics endpoint
+	metrics *fetchMetrics
+	fetcher *feedFetcher
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/michalronin/gator/internal/database"
+)
+
+const defaultTimelineLimit = 50
+
+func handlerRenderFeed(s *state, cmd command, user database.User) error {
+	if err := s.out.requireText("render-feed writes an RSS or Atom document"); err != nil {
+		return err
+	}
+	format := cmd.stringFlag("format")
+	limit := cmd.intFlag("limit")
+	if limit < 1 {
+		return invalidArgsError("limit must be a positive integer")
+	}
+	if format != "rss" && format != "atom" {
+		return invalidArgsError("unknown feed format '%s', expected rss or atom", format)
+	}
+	posts, err := listPosts(cmd.ctx, s.db, postQuery{
+		userID:   user.ID,
+		sort:     "published",
+		pageSize: int32(limit),
+	})
+	if err != nil {
+		return err
+	}
+	data, err := renderTimeline(user, posts, format, "")
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// renderTimeline encodes a user's merged timeline as an RSS 2.0 or Atom
+// document. selfURL is where the document is served from, if anywhere.
+func renderTimeline(user database.User, posts []database.GetPostsForUserByPublishedRow, format, selfURL string) ([]byte, error) {
+	title := fmt.Sprintf("gator timeline of %v", user.Name)
+	var doc interface{}
+	switch format {
+	case "rss":
+		rss := &RSSFeed{Version: "2.0"}
+		rss.Channel.Title = title
+		rss.Channel.Link = selfURL
+		rss.Channel.Description = fmt.Sprintf("Posts from the feeds %v follows, aggregated by gator", user.Name)
+		for _, post := range posts {
+			item := RSSItem{
+				Title:       post.Title,
+				Link:        post.Url,
+				Description: post.Description.String,
+				GUID:        post.Url,
+			}
+			if post.PublishedAt.Valid {
+				item.PubDate = post.PublishedAt.Time.Format(time.RFC1123Z)
+			}
+			rss.Channel.Item = append(rss.Channel.Item, item)
+		}
+		doc = rss
+	case "atom":
+		updated := time.Now()
+		if len(posts) > 0 && posts[0].PublishedAt.Valid {
+			updated = posts[0].PublishedAt.Time
+		}
+		atom := &AtomFeed{
+			Xmlns:   atomNamespace,
+			ID:      "urn:uuid:" + user.ID.String(),
+			Title:   &AtomText{Text: title},
+			Author:  &AtomPerson{Name: "gator"},
+			Updated: updated.Format(time.RFC3339),
+		}
+		if selfURL != "" {
+			atom.Links = append(atom.Links, AtomLink{Href: selfURL, Rel: "self", Type: "application/atom+xml"})
+		}
+		for _, post := range posts {
+			entry := AtomEntry{
+				ID:     "urn:uuid:" + post.ID.String(),
+				Title:  &AtomText{Text: post.Title},
+				Author: &AtomPerson{Name: post.FeedName},
+				Links:  []AtomLink{{Href: post.Url, Rel: "alternate"}},
+			}
+			if post.PublishedAt.Valid {
+				entry.Published = post.PublishedAt.Time.Format(time.RFC3339)
+				entry.Updated = entry.Published
+			} else {
+				entry.Updated = updated.Format(time.RFC3339)
+			}
+			if post.Description.String != "" {
+				entry.Summary = &AtomText{Type: "html", Text: post.Description.String}
+			}
+			atom.Entry = append(atom.Entry, entry)
+		}
+		doc = atom
+	default:
+		return nil, fmt.Errorf("unknown feed format '%s', expected rss or atom", format)
+	}
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	data = append([]byte(xml.Header), data...)
+	return append(data, '\n'), nil
+}
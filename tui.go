@@ -0,0 +1,489 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/michalronin/gator/internal/database"
+	"golang.org/x/term"
+)
+
+const (
+	tuiPostLimit       = 500
+	tuiRefreshInterval = 30 * time.Second
+)
+
+type tuiPane int
+
+const (
+	paneFeeds tuiPane = iota
+	panePosts
+	panePreview
+)
+
+type tuiKey string
+
+const (
+	keyUp    tuiKey = "up"
+	keyDown  tuiKey = "down"
+	keyLeft  tuiKey = "left"
+	keyRight tuiKey = "right"
+	keyEnter tuiKey = "enter"
+	keyTab   tuiKey = "tab"
+)
+
+// tui is the full-screen reader behind `gator tui`. It loads data with the
+// same queries as following and browse, and filters posts by feed in memory.
+type tui struct {
+	ctx  context.Context
+	s    *state
+	user database.User
+
+	feeds      []database.GetFeedFollowsForUserRow
+	posts      []database.GetPostsForUserByPublishedRow
+	unread     map[uuid.UUID]bool
+	unreadOnly bool
+
+	focus        tuiPane
+	feedCursor   int // 0 is "All posts", followed feeds start at 1
+	postCursor   int
+	postOffset   int
+	previewLine  int
+	status       string
+	width        int
+	height       int
+	lastRefresh  time.Time
+	visiblePosts []database.GetPostsForUserByPublishedRow
+}
+
+func handlerTui(s *state, cmd command, user database.User) error {
+	if err := s.out.requireText("tui is interactive"); err != nil {
+		return err
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return invalidArgsError("tui needs an interactive terminal")
+	}
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, oldState)
+	fmt.Print("\x1b[?1049h\x1b[?25l") // alternate screen, hide cursor
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	t := &tui{ctx: cmd.ctx, s: s, user: user}
+	if err := t.refresh(); err != nil {
+		return err
+	}
+	keys := make(chan tuiKey)
+	go readKeys(bufio.NewReader(os.Stdin), keys)
+	refresh := time.NewTicker(tuiRefreshInterval)
+	defer refresh.Stop()
+	resize := time.NewTicker(time.Second)
+	defer resize.Stop()
+
+	t.draw()
+	for {
+		select {
+		case <-cmd.ctx.Done():
+			return nil
+		case key, ok := <-keys:
+			if !ok || key == "q" || key == "\x03" {
+				return nil
+			}
+			t.handleKey(key)
+		case <-refresh.C:
+			if err := t.refresh(); err != nil {
+				t.status = "refresh failed: " + err.Error()
+			}
+		case <-resize.C:
+			if width, height, err := term.GetSize(fd); err != nil || (width == t.width && height == t.height) {
+				continue
+			}
+		}
+		t.draw()
+	}
+}
+
+// refresh reloads feeds and posts, keeping the selected feed and post when
+// they still exist, so new posts from a running agg show up in place.
+func (t *tui) refresh() error {
+	selectedFeed := t.selectedFeedURL()
+	var selectedPost uuid.UUID
+	if post, ok := t.selectedPost(); ok {
+		selectedPost = post.ID
+	}
+
+	feeds, err := t.s.db.GetFeedFollowsForUser(t.ctx, t.user.ID)
+	if err != nil {
+		return err
+	}
+	posts, err := listPosts(t.ctx, t.s.db, postQuery{
+		userID:   t.user.ID,
+		sort:     "published",
+		pageSize: tuiPostLimit,
+	})
+	if err != nil {
+		return err
+	}
+	unreadPosts, err := listPosts(t.ctx, t.s.db, postQuery{
+		userID:   t.user.ID,
+		sort:     "published",
+		read:     sql.NullBool{Bool: false, Valid: true},
+		pageSize: tuiPostLimit,
+	})
+	if err != nil {
+		return err
+	}
+	t.feeds = feeds
+	t.posts = posts
+	t.unread = make(map[uuid.UUID]bool)
+	for _, post := range unreadPosts {
+		t.unread[post.ID] = true
+	}
+	t.lastRefresh = time.Now()
+
+	t.feedCursor = 0
+	for i, feed := range t.feeds {
+		if feed.FeedUrl == selectedFeed {
+			t.feedCursor = i + 1
+		}
+	}
+	t.filterPosts()
+	for i, post := range t.visiblePosts {
+		if post.ID == selectedPost {
+			t.postCursor = i
+		}
+	}
+	return nil
+}
+
+func (t *tui) filterPosts() {
+	feedURL := t.selectedFeedURL()
+	t.visiblePosts = nil
+	for _, post := range t.posts {
+		if feedURL != "" && post.FeedUrl != feedURL {
+			continue
+		}
+		if t.unreadOnly && !t.unread[post.ID] {
+			continue
+		}
+		t.visiblePosts = append(t.visiblePosts, post)
+	}
+	t.postCursor = min(t.postCursor, max(len(t.visiblePosts)-1, 0))
+}
+
+func (t *tui) selectedFeedURL() string {
+	if t.feedCursor == 0 || t.feedCursor > len(t.feeds) {
+		return ""
+	}
+	return t.feeds[t.feedCursor-1].FeedUrl
+}
+
+func (t *tui) selectedPost() (database.GetPostsForUserByPublishedRow, bool) {
+	if t.postCursor >= len(t.visiblePosts) {
+		return database.GetPostsForUserByPublishedRow{}, false
+	}
+	return t.visiblePosts[t.postCursor], true
+}
+
+func (t *tui) handleKey(key tuiKey) {
+	t.status = ""
+	switch key {
+	case keyTab:
+		t.focus = (t.focus + 1) % 3
+	case keyLeft, "h":
+		t.focus = max(t.focus-1, paneFeeds)
+	case keyRight, "l":
+		t.focus = min(t.focus+1, panePreview)
+	case keyUp, "k":
+		t.move(-1)
+	case keyDown, "j":
+		t.move(1)
+	case keyEnter:
+		if t.focus == paneFeeds {
+			t.focus = panePosts
+		} else if post, ok := t.selectedPost(); ok {
+			t.focus = panePreview
+			t.previewLine = 0
+			t.markRead(post)
+		}
+	case "o":
+		if post, ok := t.selectedPost(); ok {
+			if err := openInBrowser(post.Url); err != nil {
+				t.status = "could not open browser: " + err.Error()
+			} else {
+				t.markRead(post)
+			}
+		}
+	case "u":
+		t.unreadOnly = !t.unreadOnly
+		t.postCursor, t.postOffset = 0, 0
+		t.filterPosts()
+	case "r":
+		if err := t.refresh(); err != nil {
+			t.status = "refresh failed: " + err.Error()
+		}
+	}
+}
+
+func (t *tui) move(delta int) {
+	switch t.focus {
+	case paneFeeds:
+		t.feedCursor = min(max(t.feedCursor+delta, 0), len(t.feeds))
+		t.postCursor, t.postOffset = 0, 0
+		t.filterPosts()
+	case panePosts:
+		t.postCursor = min(max(t.postCursor+delta, 0), max(len(t.visiblePosts)-1, 0))
+		t.previewLine = 0
+	case panePreview:
+		t.previewLine = max(t.previewLine+delta, 0)
+	}
+}
+
+func (t *tui) markRead(post database.GetPostsForUserByPublishedRow) {
+	if !t.unread[post.ID] {
+		return
+	}
+	if err := markPostRead(t.ctx, t.s, t.user.ID, post.ID); err != nil {
+		t.status = "could not mark post as read: " + err.Error()
+		return
+	}
+	delete(t.unread, post.ID)
+}
+
+func (t *tui) draw() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+	t.width, t.height = width, height
+	bodyHeight := max(height-2, 1)
+	feedsWidth := min(28, width/4)
+	postsWidth := (width - feedsWidth) / 2
+	previewWidth := width - feedsWidth - postsWidth - 2
+
+	feedLines := t.feedLines(feedsWidth, bodyHeight)
+	postLines := t.postLines(postsWidth, bodyHeight)
+	previewLines := t.previewLines(previewWidth, bodyHeight)
+
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	title := fmt.Sprintf(" gator - %v", t.user.Name)
+	if t.unreadOnly {
+		title += " (unread only)"
+	}
+	b.WriteString("\x1b[7m" + pad(title, width) + "\x1b[0m\r\n")
+	for i := 0; i < bodyHeight; i++ {
+		b.WriteString(feedLines[i])
+		b.WriteString("│")
+		b.WriteString(postLines[i])
+		b.WriteString("│")
+		b.WriteString(previewLines[i])
+		b.WriteString("\x1b[K\r\n")
+	}
+	status := t.status
+	if status == "" {
+		status = fmt.Sprintf("tab/←→ switch pane  ↑↓ move  enter read  o open  u unread only  r refresh  q quit  (updated %v)", t.lastRefresh.Format(time.TimeOnly))
+	}
+	b.WriteString("\x1b[7m" + pad(" "+status, width) + "\x1b[0m")
+	os.Stdout.WriteString(b.String())
+}
+
+func (t *tui) feedLines(width, height int) []string {
+	entries := []string{"All posts"}
+	for _, feed := range t.feeds {
+		count := 0
+		for _, post := range t.posts {
+			if post.FeedUrl == feed.FeedUrl && t.unread[post.ID] {
+				count++
+			}
+		}
+		name := feed.FeedName
+		if count > 0 {
+			name = fmt.Sprintf("%v (%d)", name, count)
+		}
+		entries = append(entries, name)
+	}
+	return t.listLines(entries, t.feedCursor, 0, t.focus == paneFeeds, nil, width, height)
+}
+
+func (t *tui) postLines(width, height int) []string {
+	if t.postCursor < t.postOffset {
+		t.postOffset = t.postCursor
+	} else if t.postCursor >= t.postOffset+height {
+		t.postOffset = t.postCursor - height + 1
+	}
+	var entries []string
+	bold := make(map[int]bool)
+	for i, post := range t.visiblePosts {
+		entries = append(entries, post.Title)
+		bold[i] = t.unread[post.ID]
+	}
+	if len(entries) == 0 {
+		entries = append(entries, "no posts")
+	}
+	return t.listLines(entries, t.postCursor, t.postOffset, t.focus == panePosts, bold, width, height)
+}
+
+func (t *tui) listLines(entries []string, cursor, offset int, focused bool, bold map[int]bool, width, height int) []string {
+	lines := make([]string, height)
+	for i := range lines {
+		index := offset + i
+		if index >= len(entries) {
+			lines[i] = strings.Repeat(" ", width)
+			continue
+		}
+		line := pad(" "+entries[index], width)
+		switch {
+		case index == cursor && focused:
+			line = "\x1b[7m" + line + "\x1b[0m"
+		case index == cursor:
+			line = "\x1b[4m" + line + "\x1b[0m"
+		case bold[index]:
+			line = "\x1b[1m" + line + "\x1b[0m"
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+func (t *tui) previewLines(width, height int) []string {
+	var text []string
+	if post, ok := t.selectedPost(); ok {
+		text = append(text, wrap(post.Title, width-1)...)
+		meta := post.FeedName
+		if post.PublishedAt.Valid {
+			meta += " · " + post.PublishedAt.Time.Format("2 Jan 2006 15:04")
+		}
+		text = append(text, meta, post.Url, "")
+		text = append(text, wrap(plainText(post.Description.String), width-1)...)
+	}
+	t.previewLine = min(t.previewLine, max(len(text)-height, 0))
+	lines := make([]string, height)
+	for i := range lines {
+		line := ""
+		if t.previewLine+i < len(text) {
+			line = text[t.previewLine+i]
+		}
+		lines[i] = pad(" "+line, width)
+	}
+	return lines
+}
+
+// readKeys turns raw terminal input into keys, decoding the escape
+// sequences sent by arrow keys.
+func readKeys(reader *bufio.Reader, keys chan<- tuiKey) {
+	defer close(keys)
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			return
+		}
+		switch r {
+		case '\r', '\n':
+			keys <- keyEnter
+		case '\t':
+			keys <- keyTab
+		case '\x1b':
+			if reader.Buffered() < 2 {
+				keys <- "esc"
+				continue
+			}
+			bracket, _ := reader.ReadByte()
+			code, _ := reader.ReadByte()
+			if bracket != '[' && bracket != 'O' {
+				continue
+			}
+			switch code {
+			case 'A':
+				keys <- keyUp
+			case 'B':
+				keys <- keyDown
+			case 'C':
+				keys <- keyRight
+			case 'D':
+				keys <- keyLeft
+			}
+		default:
+			keys <- tuiKey(string(r))
+		}
+	}
+}
+
+// openInBrowser only opens web links, since the url comes from the feed and
+// could otherwise name a local file or pass an option to the opener.
+func openInBrowser(postURL string) error {
+	u, err := url.Parse(postURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("not a web link: %q", postURL)
+	}
+	link := u.String()
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", link)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	return cmd.Start()
+}
+
+// pad cuts or pads s to exactly width columns, assuming one column per rune.
+func pad(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	runes := []rune(stripControl(s))
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return string(runes) + strings.Repeat(" ", width-len(runes))
+}
+
+// stripControl removes control characters, ESC included, so that text from
+// feeds can't send escape sequences to the terminal; tabs and line breaks
+// become spaces.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func wrap(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(stripControl(text)) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
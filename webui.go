@@ -0,0 +1,374 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"html"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/michalronin/gator/internal/database"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+const (
+	webPageSize     = 20
+	tokenCookieName = "gator_token"
+)
+
+var webTemplates = map[string]*template.Template{
+	"login": parseWebTemplate("login.html"),
+	"posts": parseWebTemplate("posts.html"),
+	"feeds": parseWebTemplate("feeds.html"),
+}
+
+func parseWebTemplate(page string) *template.Template {
+	return template.Must(template.New("layout.html").Funcs(template.FuncMap{
+		"date": func(t sql.NullTime) string {
+			if !t.Valid {
+				return ""
+			}
+			return t.Time.Format("2 Jan 2006 15:04")
+		},
+		"excerpt": excerpt,
+	}).ParseFS(templateFS, "templates/layout.html", "templates/"+page))
+}
+
+type webSidebarFeed struct {
+	Name   string
+	Url    string
+	Folder string
+	Unread int64
+}
+
+type webPage struct {
+	Title    string
+	User     *database.User
+	Feeds    []webSidebarFeed
+	Error    string
+	Current  string
+	Posts    []database.GetPostPageForUserRow
+	Page     int
+	PrevPage string
+	NextPage string
+	AllFeeds []webFeed
+}
+
+type webFeed struct {
+	Name      string
+	Url       string
+	CreatedBy string
+	Following bool
+}
+
+func (api *apiServer) webRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /{$}", http.RedirectHandler("/ui/", http.StatusFound))
+	mux.HandleFunc("GET /ui/login", api.handleWebLoginForm)
+	mux.HandleFunc("POST /ui/login", api.handleWebLogin)
+	mux.HandleFunc("POST /ui/logout", api.handleWebLogout)
+	mux.HandleFunc("GET /ui/{$}", api.webAuthenticated(api.handleWebPosts))
+	mux.HandleFunc("GET /ui/feeds", api.webAuthenticated(api.handleWebFeeds))
+	mux.HandleFunc("GET /ui/posts/{id}", api.webAuthenticated(api.handleWebOpenPost))
+	mux.HandleFunc("POST /ui/follow", api.webAuthenticated(api.handleWebFollow))
+	mux.HandleFunc("POST /ui/unfollow", api.webAuthenticated(api.handleWebUnfollow))
+}
+
+// webAuthenticated is the browser counterpart of authenticated; the API
+// token is kept in a cookie set by the login form.
+func (api *apiServer) webAuthenticated(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(tokenCookieName)
+		if err != nil {
+			http.Redirect(w, r, "/ui/login", http.StatusFound)
+			return
+		}
+		user, err := api.s.db.GetUserByApiToken(r.Context(), database.GetUserByApiTokenParams{
+			LastUsedAt: sql.NullTime{Time: time.Now(), Valid: true},
+			TokenHash:  hashToken(cookie.Value),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Redirect(w, r, "/ui/login", http.StatusFound)
+			return
+		}
+		if err != nil {
+			webError(w, err)
+			return
+		}
+		handler(w, r, user)
+	}
+}
+
+func (api *apiServer) handleWebLoginForm(w http.ResponseWriter, r *http.Request) {
+	renderWebPage(w, "login", webPage{Title: "Log in"})
+}
+
+func (api *apiServer) handleWebLogin(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSpace(r.FormValue("token"))
+	_, err := api.s.db.GetUserByApiToken(r.Context(), database.GetUserByApiTokenParams{
+		LastUsedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		TokenHash:  hashToken(token),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		w.WriteHeader(http.StatusUnauthorized)
+		renderWebPage(w, "login", webPage{Title: "Log in", Error: "Invalid or revoked token."})
+		return
+	}
+	if err != nil {
+		webError(w, err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     tokenCookieName,
+		Value:    token,
+		Path:     "/ui/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(w, r, "/ui/", http.StatusSeeOther)
+}
+
+func (api *apiServer) handleWebLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   tokenCookieName,
+		Path:   "/ui/",
+		MaxAge: -1,
+	})
+	http.Redirect(w, r, "/ui/login", http.StatusSeeOther)
+}
+
+func (api *apiServer) handleWebPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	params := database.GetPostPageForUserParams{
+		UserID:     user.ID,
+		PageSize:   webPageSize + 1, // one extra row tells us whether there is a next page
+		PageOffset: int32((page - 1) * webPageSize),
+	}
+	feedURL := r.URL.Query().Get("feed")
+	if feedURL != "" {
+		params.FeedUrl = sql.NullString{String: feedURL, Valid: true}
+	}
+	posts, err := api.s.db.GetPostPageForUser(r.Context(), params)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+	feeds, err := api.sidebarFeeds(r, user)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+
+	data := webPage{
+		Title:   "Posts",
+		User:    &user,
+		Feeds:   feeds,
+		Current: feedURL,
+		Page:    page,
+	}
+	if feedURL != "" {
+		for _, feed := range feeds {
+			if feed.Url == feedURL {
+				data.Title = feed.Name
+			}
+		}
+	}
+	if len(posts) > webPageSize {
+		posts = posts[:webPageSize]
+		data.NextPage = postsPageURL(feedURL, page+1)
+	}
+	if page > 1 {
+		data.PrevPage = postsPageURL(feedURL, page-1)
+	}
+	data.Posts = posts
+	renderWebPage(w, "posts", data)
+}
+
+func (api *apiServer) handleWebFeeds(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := api.sidebarFeeds(r, user)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+	following := make(map[string]bool)
+	for _, feed := range feeds {
+		following[feed.Url] = true
+	}
+	allFeeds, err := api.s.db.GetFeeds(r.Context())
+	if err != nil {
+		webError(w, err)
+		return
+	}
+	data := webPage{
+		Title: "All feeds",
+		User:  &user,
+		Feeds: feeds,
+	}
+	for _, feed := range allFeeds {
+		data.AllFeeds = append(data.AllFeeds, webFeed{
+			Name:      feed.Name,
+			Url:       feed.Url,
+			CreatedBy: feed.Username,
+			Following: following[feed.Url],
+		})
+	}
+	renderWebPage(w, "feeds", data)
+}
+
+// handleWebOpenPost marks a post as read on its way to the original article.
+func (api *apiServer) handleWebOpenPost(w http.ResponseWriter, r *http.Request, user database.User) {
+	postID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	postURL, err := api.s.db.GetPostUrl(r.Context(), postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		webError(w, err)
+		return
+	}
+	if err := markPostRead(r.Context(), api.s, user.ID, postID); err != nil {
+		webError(w, err)
+		return
+	}
+	http.Redirect(w, r, postURL, http.StatusFound)
+}
+
+func (api *apiServer) handleWebFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedToFollow, err := api.s.db.GetFeedByUrl(r.Context(), r.FormValue("url"))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		webError(w, err)
+		return
+	}
+	if _, err := api.s.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feedToFollow.ID,
+	}); err != nil {
+		if pqErr, ok := err.(*pq.Error); !ok || pqErr.Code != "23505" { // following it already is fine
+			webError(w, err)
+			return
+		}
+	}
+	redirectBack(w, r, "/ui/feeds")
+}
+
+func (api *apiServer) handleWebUnfollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	// not following it anymore is fine
+	if _, err := api.s.db.DeleteFeedFollow(r.Context(), database.DeleteFeedFollowParams{
+		UserID: user.ID,
+		Url:    r.FormValue("url"),
+	}); err != nil {
+		webError(w, err)
+		return
+	}
+	redirectBack(w, r, "/ui/feeds")
+}
+
+func (api *apiServer) sidebarFeeds(r *http.Request, user database.User) ([]webSidebarFeed, error) {
+	feedsFollowed, err := api.s.db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	unreadCounts, err := api.s.db.CountUnreadPostsByFeed(r.Context(), user.ID)
+	if err != nil {
+		return nil, err
+	}
+	unread := make(map[string]int64)
+	for _, count := range unreadCounts {
+		unread[count.Url] = count.Unread
+	}
+	var feeds []webSidebarFeed
+	for _, feed := range feedsFollowed {
+		feeds = append(feeds, webSidebarFeed{
+			Name:   feed.FeedName,
+			Url:    feed.FeedUrl,
+			Folder: feed.Folder.String,
+			Unread: unread[feed.FeedUrl],
+		})
+	}
+	slices.SortFunc(feeds, func(a, b webSidebarFeed) int {
+		if a.Folder != b.Folder {
+			return strings.Compare(a.Folder, b.Folder)
+		}
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return feeds, nil
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// plainText strips the markup from a post description, which is usually
+// HTML.
+func plainText(description string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(htmlTag.ReplaceAllString(description, " "))), " ")
+}
+
+// excerpt is a short plain-text preview of a post description.
+func excerpt(description string) string {
+	return shorten(plainText(description), 300)
+}
+
+// shorten cuts text down to at most n characters, marking the cut.
+func shorten(text string, n int) string {
+	if runes := []rune(text); len(runes) > n {
+		return string(runes[:n]) + "…"
+	}
+	return text
+}
+
+func postsPageURL(feedURL string, page int) string {
+	query := url.Values{}
+	if feedURL != "" {
+		query.Set("feed", feedURL)
+	}
+	query.Set("page", strconv.Itoa(page))
+	return "/ui/?" + query.Encode()
+}
+
+// redirectBack returns to the page the form was submitted from, as long as
+// it is part of the web UI.
+func redirectBack(w http.ResponseWriter, r *http.Request, fallback string) {
+	target := fallback
+	if referer, err := url.Parse(r.Referer()); err == nil && referer.Host == r.Host && strings.HasPrefix(referer.Path, "/ui/") {
+		target = referer.RequestURI()
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+func renderWebPage(w http.ResponseWriter, name string, data webPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webTemplates[name].Execute(w, data); err != nil {
+		log.Println("web error:", err)
+	}
+}
+
+func webError(w http.ResponseWriter, err error) {
+	log.Println("web error:", err)
+	http.Error(w, "internal server error", http.StatusInternalServerError)
+}